@@ -0,0 +1,36 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/codeGROOVE-dev/turnclient/pkg/turn"
+)
+
+// actionLines formats each assigned action as "user: kind (reason)", with
+// critical actions first and then by user.
+func actionLines(r *turn.CheckResponse) []string {
+	actions := r.Analysis.NextAction
+	users := slices.SortedFunc(maps.Keys(actions), func(a, b string) int {
+		if actions[a].Critical != actions[b].Critical {
+			if actions[a].Critical {
+				return -1
+			}
+			return 1
+		}
+		return cmp.Compare(a, b)
+	})
+
+	lines := make([]string, 0, len(users))
+	for _, u := range users {
+		a := actions[u]
+		line := fmt.Sprintf("%s: %s", u, a.Kind)
+		if a.Reason != "" {
+			line += " (" + a.Reason + ")"
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/codeGROOVE-dev/turnclient/pkg/turn"
+)
+
+func TestActionLines(t *testing.T) {
+	r := &turn.CheckResponse{Analysis: turn.Analysis{NextAction: map[string]turn.Action{
+		"carol": {Kind: turn.ActionReview, Reason: "requested reviewer"},
+		"bob":   {Kind: turn.ActionFixTests, Reason: "2 checks failing", Critical: true},
+		"alice": {Kind: turn.ActionMerge},
+		"dave":  {Kind: turn.ActionRespond, Critical: true},
+	}}}
+	want := []string{
+		"bob: fix_tests (2 checks failing)",
+		"dave: respond",
+		"alice: merge",
+		"carol: review (requested reviewer)",
+	}
+	if got := actionLines(r); !slices.Equal(got, want) {
+		t.Errorf("actionLines() = %q, want %q", got, want)
+	}
+	if got := actionLines(&turn.CheckResponse{}); len(got) != 0 {
+		t.Errorf("actionLines() with no actions = %q, want empty", got)
+	}
+}
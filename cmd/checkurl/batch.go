@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/codeGROOVE-dev/turnclient/pkg/turn"
+)
+
+// batch checks a list of PRs read from stdin, one per line.
+type batch struct {
+	checker      turn.Checker
+	logger       *log.Logger
+	out          io.Writer // Receives one result per line
+	errOut       io.Writer // Receives per-entry errors
+	user         string
+	host         string
+	get          string
+	format       string    // "yaml" writes a YAML document per result; otherwise JSON lines
+	actionsOnly  bool      // Write "url<TAB>user: kind (reason)" lines instead of full results
+	refTime      time.Time // Default updatedAt for lines without a timestamp
+	updatedAfter time.Time // Entries updated before this are skipped; zero disables
+}
+
+// batchEntry is a single PR to check.
+type batchEntry struct {
+	updatedAt time.Time
+	prURL     string
+}
+
+// parseBatchLine parses a line of the form "<pr-ref>[<TAB><updated-at>]",
+// where updated-at is RFC3339. If the timestamp column is absent, defaultTime is used.
+func parseBatchLine(line, host string, defaultTime time.Time) (batchEntry, error) {
+	ref, ts, hasTS := strings.Cut(line, "\t")
+	prURL, err := normalizePRRef(strings.TrimSpace(ref), host)
+	if err != nil {
+		return batchEntry{}, err
+	}
+	if err := validatePRURL(prURL, host); err != nil {
+		return batchEntry{}, err
+	}
+
+	updatedAt := defaultTime
+	if ts = strings.TrimSpace(ts); hasTS && ts != "" {
+		updatedAt, err = time.Parse(time.RFC3339, ts)
+		if err != nil {
+			return batchEntry{}, fmt.Errorf("invalid updated_at %q: %w", ts, err)
+		}
+	}
+	return batchEntry{prURL: prURL, updatedAt: updatedAt}, nil
+}
+
+// run checks every entry read from in. Blank lines and lines starting with #
+// are ignored. Failed entries are reported to errOut and do not stop the batch.
+func (b *batch) run(ctx context.Context, in io.Reader) error {
+	scanner := bufio.NewScanner(in)
+	var total, failed, skipped int
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if ctx.Err() != nil {
+			return errors.New("interrupted")
+		}
+		total++
+
+		entry, err := parseBatchLine(line, b.host, b.refTime)
+		if err != nil {
+			fmt.Fprintf(b.errOut, "error: %s: %v\n", line, err)
+			failed++
+			continue
+		}
+		if !b.updatedAfter.IsZero() && entry.updatedAt.Before(b.updatedAfter) {
+			b.logger.Printf("skipping %s: updated %s, before cutoff", entry.prURL, entry.updatedAt.Format(time.RFC3339))
+			skipped++
+			continue
+		}
+
+		if err := b.check(ctx, entry); err != nil {
+			fmt.Fprintf(b.errOut, "error: %s: %v\n", entry.prURL, err)
+			failed++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("reading input: %w", err)
+	}
+
+	b.logger.Printf("batch complete: %d entries, %d skipped, %d failed", total, skipped, failed)
+	if failed > 0 {
+		return fmt.Errorf("%d of %d checks failed", failed, total)
+	}
+	return nil
+}
+
+// check runs a single entry with its own timeout and writes the result.
+func (b *batch) check(ctx context.Context, entry batchEntry) error {
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+
+	result, err := b.checker.Check(ctx, entry.prURL, b.user, entry.updatedAt)
+	if err != nil {
+		return err
+	}
+
+	if b.get != "" {
+		val, err := resolveField(result, b.get)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(b.out, "%s\t%s\n", entry.prURL, val)
+		return err
+	}
+	if b.actionsOnly {
+		for _, line := range actionLines(result) {
+			if _, err := fmt.Fprintf(b.out, "%s\t%s\n", entry.prURL, line); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if b.format == "yaml" {
+		if _, err := io.WriteString(b.out, "---\n"); err != nil {
+			return err
+		}
+		if err := writeYAML(b.out, result); err != nil {
+			return fmt.Errorf("encoding response: %w", err)
+		}
+		return nil
+	}
+	if err := json.NewEncoder(b.out).Encode(result); err != nil {
+		return fmt.Errorf("encoding response: %w", err)
+	}
+	return nil
+}
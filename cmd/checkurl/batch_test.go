@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"io"
+	"log"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/codeGROOVE-dev/turnclient/pkg/turn"
+	"github.com/codeGROOVE-dev/turnclient/pkg/turn/turntest"
+)
+
+func TestParseBatchLine(t *testing.T) {
+	now := time.Date(2025, 3, 16, 6, 18, 8, 0, time.UTC)
+	tests := []struct {
+		name    string
+		line    string
+		wantURL string
+		wantAt  time.Time
+		wantErr bool
+	}{
+		{
+			name:    "URL only",
+			line:    "https://github.com/owner/repo/pull/1",
+			wantURL: "https://github.com/owner/repo/pull/1",
+			wantAt:  now,
+		},
+		{
+			name:    "URL and timestamp",
+			line:    "https://github.com/owner/repo/pull/2\t2024-01-02T03:04:05Z",
+			wantURL: "https://github.com/owner/repo/pull/2",
+			wantAt:  time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		},
+		{
+			name:    "shorthand with empty timestamp column",
+			line:    "owner/repo#3\t",
+			wantURL: "https://github.com/owner/repo/pull/3",
+			wantAt:  now,
+		},
+		{
+			name:    "bad timestamp",
+			line:    "owner/repo#4\tyesterday",
+			wantErr: true,
+		},
+		{
+			name:    "not a PR",
+			line:    "https://github.com/owner/repo",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseBatchLine(tt.line, defaultGitHubHost, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseBatchLine() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got.prURL != tt.wantURL || !got.updatedAt.Equal(tt.wantAt) {
+				t.Errorf("parseBatchLine() = %q %v, want %q %v", got.prURL, got.updatedAt, tt.wantURL, tt.wantAt)
+			}
+		})
+	}
+}
+
+func TestBatchUpdatedAfter(t *testing.T) {
+	fake := &turntest.FakeClient{
+		Responses: map[string]*turn.CheckResponse{
+			"https://github.com/owner/repo/pull/1": {Analysis: turn.Analysis{ReadyToMerge: true}},
+			"https://github.com/owner/repo/pull/3": {},
+		},
+	}
+	var out, errOut strings.Builder
+	b := &batch{
+		checker:      fake,
+		logger:       log.New(io.Discard, "", 0),
+		out:          &out,
+		errOut:       &errOut,
+		user:         "alice",
+		host:         defaultGitHubHost,
+		get:          "ready_to_merge",
+		refTime:      time.Now(),
+		updatedAfter: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	in := strings.Join([]string{
+		"# comment",
+		"owner/repo#1\t2024-07-01T00:00:00Z",
+		"owner/repo#2\t2024-01-01T00:00:00Z", // skipped: before cutoff
+		"",
+		"owner/repo#3", // no timestamp: defaults to now
+	}, "\n")
+
+	if err := b.run(context.Background(), strings.NewReader(in)); err != nil {
+		t.Fatalf("run() failed: %v (stderr: %s)", err, errOut.String())
+	}
+
+	want := "https://github.com/owner/repo/pull/1\ttrue\nhttps://github.com/owner/repo/pull/3\tfalse\n"
+	if out.String() != want {
+		t.Errorf("output = %q, want %q", out.String(), want)
+	}
+	if calls := fake.Calls(); len(calls) != 2 {
+		t.Errorf("made %d checks, want 2", len(calls))
+	}
+}
+
+func TestBatchErrors(t *testing.T) {
+	var out, errOut strings.Builder
+	b := &batch{
+		checker: &turntest.FakeClient{},
+		logger:  log.New(io.Discard, "", 0),
+		out:     &out,
+		errOut:  &errOut,
+		user:    "alice",
+		host:    defaultGitHubHost,
+		refTime: time.Now(),
+	}
+
+	err := b.run(context.Background(), strings.NewReader("owner/repo#1\nnot-a-pr\n"))
+	if err == nil || !strings.Contains(err.Error(), "2 of 2 checks failed") {
+		t.Errorf("run() error = %v, want 2 of 2 failed", err)
+	}
+	if strings.Count(errOut.String(), "error:") != 2 {
+		t.Errorf("stderr = %q, want two errors", errOut.String())
+	}
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/codeGROOVE-dev/turnclient/pkg/turn"
+)
+
+const doctorCheckTimeout = 10 * time.Second
+
+// doctorClient is the part of *turn.Client that doctor exercises.
+type doctorClient interface {
+	CurrentUser(ctx context.Context) (string, error)
+	ServerInfo(ctx context.Context) (turn.ServerInfo, error)
+}
+
+// doctor runs connectivity and auth checks against the backend and writes a
+// pass/fail line for each, for --doctor.
+type doctor struct {
+	client   doctorClient
+	out      io.Writer
+	backend  string
+	hasToken bool
+	insecure bool // Mirrors --insecure, so the TLS check verifies what the client would
+}
+
+// doctorCheck is a single named check; run returns a short detail on success.
+type doctorCheck struct {
+	run  func(ctx context.Context) (string, error)
+	name string
+}
+
+// run performs every check, even after a failure, so the report is complete.
+func (d *doctor) run(ctx context.Context) error {
+	u, err := url.Parse(d.backend)
+	if err != nil {
+		return fmt.Errorf("invalid backend URL: %w", err)
+	}
+
+	checks := []doctorCheck{
+		{name: "dns", run: func(ctx context.Context) (string, error) { return checkDNS(ctx, u) }},
+		{name: "tls", run: func(ctx context.Context) (string, error) { return checkTLS(ctx, u, d.insecure) }},
+		{name: "auth", run: d.checkAuth},
+		{name: "backend", run: d.checkBackend},
+	}
+	failed := 0
+	for _, c := range checks {
+		cctx, cancel := context.WithTimeout(ctx, doctorCheckTimeout)
+		detail, err := c.run(cctx)
+		cancel()
+		status := "PASS"
+		if err != nil {
+			status, detail = "FAIL", err.Error()
+			failed++
+		}
+		if _, err := fmt.Fprintf(d.out, "%s  %-8s %s\n", status, c.name, detail); err != nil {
+			return err
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d checks failed", failed, len(checks))
+	}
+	return nil
+}
+
+// checkDNS resolves the backend host.
+func checkDNS(ctx context.Context, u *url.URL) (string, error) {
+	host := u.Hostname()
+	if net.ParseIP(host) != nil {
+		return fmt.Sprintf("skipped, %s is an IP address", host), nil
+	}
+	addrs, err := net.DefaultResolver.LookupHost(ctx, host)
+	if err != nil {
+		return "", fmt.Errorf("resolving %s: %w", host, err)
+	}
+	return fmt.Sprintf("%s resolves to %s", host, strings.Join(addrs, ", ")), nil
+}
+
+// checkTLS completes a TLS handshake with the backend and reports the
+// certificate it presented.
+func checkTLS(ctx context.Context, u *url.URL, insecure bool) (string, error) {
+	if u.Scheme != "https" {
+		return "skipped, backend uses " + u.Scheme, nil
+	}
+	addr := u.Host
+	if u.Port() == "" {
+		addr = net.JoinHostPort(u.Hostname(), "443")
+	}
+	dialer := &tls.Dialer{Config: &tls.Config{
+		ServerName:         u.Hostname(),
+		InsecureSkipVerify: insecure, //nolint:gosec // opt-in via --insecure, matching the client
+		MinVersion:         tls.VersionTLS12,
+	}}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return "", fmt.Errorf("TLS handshake with %s: %w", addr, err)
+	}
+	state := conn.(*tls.Conn).ConnectionState() //nolint:forcetypeassert // tls.Dialer always returns a *tls.Conn
+	if err := conn.Close(); err != nil {
+		return "", fmt.Errorf("closing connection to %s: %w", addr, err)
+	}
+
+	detail := tls.VersionName(state.Version)
+	if len(state.PeerCertificates) > 0 {
+		cert := state.PeerCertificates[0]
+		detail += fmt.Sprintf(", certificate for %s valid until %s", cert.Subject.CommonName, cert.NotAfter.Format(time.DateOnly))
+	}
+	if insecure {
+		detail += " (not verified: --insecure)"
+	}
+	return detail, nil
+}
+
+// checkAuth verifies the GitHub token by looking up the user it belongs to.
+func (d *doctor) checkAuth(ctx context.Context) (string, error) {
+	if !d.hasToken {
+		return "", errors.New("no GitHub token found; run 'gh auth login' or set GITHUB_TOKEN")
+	}
+	user, err := d.client.CurrentUser(ctx)
+	if err != nil {
+		return "", err
+	}
+	return "authenticated as " + user, nil
+}
+
+// checkBackend makes a lightweight request to the backend, one that does not
+// run an analysis.
+func (d *doctor) checkBackend(ctx context.Context) (string, error) {
+	info, err := d.client.ServerInfo(ctx)
+	if errors.Is(err, turn.ErrNotSupported) {
+		return "reachable (server does not report its version)", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if info.Version == "" {
+		return "reachable", nil
+	}
+	return "reachable, version " + info.Version, nil
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/codeGROOVE-dev/turnclient/pkg/turn"
+)
+
+// fakeDoctorClient returns canned results for doctor's checks.
+type fakeDoctorClient struct {
+	userErr error
+	infoErr error
+	user    string
+	info    turn.ServerInfo
+}
+
+func (f *fakeDoctorClient) CurrentUser(context.Context) (string, error) {
+	return f.user, f.userErr
+}
+
+func (f *fakeDoctorClient) ServerInfo(context.Context) (turn.ServerInfo, error) {
+	return f.info, f.infoErr
+}
+
+func TestDoctor(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.NotFoundHandler())
+	server.Config.ErrorLog = log.New(io.Discard, "", 0) // Quiet the rejected handshake
+	server.StartTLS()
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		backend  string
+		client   *fakeDoctorClient
+		hasToken bool
+		insecure bool
+		want     []string
+		wantErr  bool
+	}{
+		{
+			name:     "all pass",
+			backend:  server.URL,
+			client:   &fakeDoctorClient{user: "alice", info: turn.ServerInfo{Version: "v1.2.3"}},
+			hasToken: true,
+			insecure: true,
+			want: []string{
+				"PASS  dns      skipped, 127.0.0.1 is an IP address",
+				"PASS  tls      TLS 1.3",
+				"PASS  auth     authenticated as alice",
+				"PASS  backend  reachable, version v1.2.3",
+			},
+		},
+		{
+			name:     "untrusted certificate and bad token",
+			backend:  server.URL,
+			client:   &fakeDoctorClient{userErr: errors.New("401 bad credentials"), infoErr: fmt.Errorf("server info: %w", turn.ErrNotSupported)},
+			hasToken: true,
+			want: []string{
+				"FAIL  tls      TLS handshake with 127.0.0.1",
+				"FAIL  auth     401 bad credentials",
+				"PASS  backend  reachable (server does not report its version)",
+			},
+			wantErr: true,
+		},
+		{
+			name:    "plain http without a token",
+			backend: "http://127.0.0.1:1",
+			client:  &fakeDoctorClient{infoErr: errors.New("connection refused")},
+			want: []string{
+				"PASS  tls      skipped, backend uses http",
+				"FAIL  auth     no GitHub token found",
+				"FAIL  backend  connection refused",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			d := &doctor{client: tt.client, out: &out, backend: tt.backend, hasToken: tt.hasToken, insecure: tt.insecure}
+			err := d.run(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("report missing %q:\n%s", want, out.String())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// resolveField looks up a dotted path of JSON field names (such as
+// analysis.checks.failing) in v and returns it formatted for printing.
+// Paths that don't match from the top level are also tried under "analysis",
+// so "ready_to_merge" works as well as "analysis.ready_to_merge".
+func resolveField(v any, path string) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("encoding response: %w", err)
+	}
+	var doc any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return "", fmt.Errorf("decoding response: %w", err)
+	}
+
+	val, ok := lookupPath(doc, path)
+	if !ok {
+		val, ok = lookupPath(doc, "analysis."+path)
+	}
+	if !ok {
+		return "", fmt.Errorf("field %q not found in response", path)
+	}
+
+	// Strings print bare; numbers, bools, objects and arrays print as JSON.
+	if s, ok := val.(string); ok {
+		return s, nil
+	}
+	out, err := json.Marshal(val)
+	if err != nil {
+		return "", fmt.Errorf("encoding field %q: %w", path, err)
+	}
+	return string(out), nil
+}
+
+func lookupPath(doc any, path string) (any, bool) {
+	for key := range strings.SplitSeq(path, ".") {
+		m, ok := doc.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if doc, ok = m[key]; !ok {
+			return nil, false
+		}
+	}
+	return doc, true
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/codeGROOVE-dev/turnclient/pkg/turn"
+)
+
+func TestResolveField(t *testing.T) {
+	result := &turn.CheckResponse{
+		Commit: "abc123",
+		Analysis: turn.Analysis{
+			ReadyToMerge: true,
+			Checks:       turn.Checks{Failing: 3},
+			Tags:         []string{"draft", "large"},
+		},
+	}
+
+	tests := []struct {
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{path: "analysis.ready_to_merge", want: "true"},
+		{path: "ready_to_merge", want: "true"},
+		{path: "analysis.checks.failing", want: "3"},
+		{path: "checks.failing", want: "3"},
+		{path: "commit", want: "abc123"},
+		{path: "analysis.tags", want: `["draft","large"]`},
+		{path: "analysis.nope", wantErr: true},
+		{path: "commit.length", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := resolveField(result, tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveField(%q) error = %v, wantErr %v", tt.path, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("resolveField(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/codeGROOVE-dev/turnclient/pkg/turn"
+)
+
+// Exit statuses. exitGateFailed lets CI tell a PR that failed --fail-on
+// apart from a check that could not be completed.
+const (
+	exitFailure    = 1
+	exitGateFailed = 2
+)
+
+// gateError reports that the PR failed the --fail-on gate, as opposed to an
+// operational failure such as a network or auth error.
+type gateError struct {
+	err error
+}
+
+func (e *gateError) Error() string { return e.err.Error() }
+
+func (e *gateError) Unwrap() error { return e.err }
+
+// exitCode returns the process exit status for an error returned by run.
+func exitCode(err error) int {
+	var ge *gateError
+	if errors.As(err, &ge) {
+		return exitGateFailed
+	}
+	return exitFailure
+}
+
+// gates maps each --fail-on value to a check that returns an error when the
+// PR should fail the gate.
+var gates = map[string]func(*turn.CheckResponse) error{
+	// blocking fails when anyone has an assigned action.
+	"blocking": func(r *turn.CheckResponse) error {
+		if n := len(r.Analysis.NextAction); n > 0 {
+			return fmt.Errorf("found %d blocking actions", n)
+		}
+		return nil
+	},
+	// not-ready fails unless the backend considers the PR ready to merge.
+	"not-ready": func(r *turn.CheckResponse) error {
+		if !r.Analysis.ReadyToMerge {
+			return errors.New("PR is not ready to merge")
+		}
+		return nil
+	},
+	// failing-tests fails when any check is failing.
+	"failing-tests": func(r *turn.CheckResponse) error {
+		if n := r.Analysis.Checks.Failing; n > 0 {
+			return fmt.Errorf("found %d failing checks", n)
+		}
+		return nil
+	},
+	// any-action is the strictest gate: it also fails on outstanding work
+	// that nobody has been assigned yet.
+	"any-action": func(r *turn.CheckResponse) error {
+		a := r.Analysis
+		switch {
+		case len(a.NextAction) > 0:
+			return fmt.Errorf("found %d actions", len(a.NextAction))
+		case a.UnresolvedComments > 0:
+			return fmt.Errorf("found %d unresolved comments", a.UnresolvedComments)
+		case a.Checks.Failing > 0:
+			return fmt.Errorf("found %d failing checks", a.Checks.Failing)
+		case a.MergeConflict:
+			return errors.New("PR has a merge conflict")
+		default:
+			return nil
+		}
+	},
+}
+
+// gateFor returns the gate for a --fail-on value. Failures are returned as a
+// *gateError.
+func gateFor(name string) (func(*turn.CheckResponse) error, error) {
+	g, ok := gates[name]
+	if !ok {
+		names := make([]string, 0, len(gates))
+		for n := range gates {
+			names = append(names, n)
+		}
+		slices.Sort(names)
+		return nil, fmt.Errorf("invalid --fail-on value %q (expected one of %s)", name, strings.Join(names, ", "))
+	}
+	return func(r *turn.CheckResponse) error {
+		if err := g(r); err != nil {
+			return &gateError{err: err}
+		}
+		return nil
+	}, nil
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/codeGROOVE-dev/turnclient/pkg/turn"
+)
+
+func TestGates(t *testing.T) {
+	clean := &turn.CheckResponse{Analysis: turn.Analysis{ReadyToMerge: true}}
+	assigned := &turn.CheckResponse{Analysis: turn.Analysis{
+		NextAction:   map[string]turn.Action{"alice": {Kind: turn.ActionReview}},
+		ReadyToMerge: true,
+	}}
+	failing := &turn.CheckResponse{Analysis: turn.Analysis{Checks: turn.Checks{Failing: 2}}}
+	comments := &turn.CheckResponse{Analysis: turn.Analysis{UnresolvedComments: 1, ReadyToMerge: true}}
+
+	tests := []struct {
+		gate string
+		resp *turn.CheckResponse
+		fail bool
+	}{
+		{gate: "blocking", resp: clean, fail: false},
+		{gate: "blocking", resp: assigned, fail: true},
+		{gate: "blocking", resp: failing, fail: false},
+		{gate: "not-ready", resp: clean, fail: false},
+		{gate: "not-ready", resp: failing, fail: true},
+		{gate: "failing-tests", resp: assigned, fail: false},
+		{gate: "failing-tests", resp: failing, fail: true},
+		{gate: "any-action", resp: clean, fail: false},
+		{gate: "any-action", resp: assigned, fail: true},
+		{gate: "any-action", resp: comments, fail: true},
+	}
+	for _, tt := range tests {
+		g, err := gateFor(tt.gate)
+		if err != nil {
+			t.Fatalf("gateFor(%q) failed: %v", tt.gate, err)
+		}
+		err = g(tt.resp)
+		if (err != nil) != tt.fail {
+			t.Errorf("%s gate on %+v = %v, want fail=%v", tt.gate, tt.resp.Analysis, err, tt.fail)
+		}
+		if tt.fail && exitCode(err) != exitGateFailed {
+			t.Errorf("%s gate failure exits %d, want %d", tt.gate, exitCode(err), exitGateFailed)
+		}
+	}
+
+	if _, err := gateFor("sometimes"); err == nil {
+		t.Error("gateFor() with unknown value should fail")
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	if got := exitCode(errors.New("connection refused")); got != exitFailure {
+		t.Errorf("exitCode(operational error) = %d, want %d", got, exitFailure)
+	}
+	if got := exitCode(fmt.Errorf("wrapped: %w", &gateError{err: errors.New("not ready")})); got != exitGateFailed {
+		t.Errorf("exitCode(gate error) = %d, want %d", got, exitGateFailed)
+	}
+}
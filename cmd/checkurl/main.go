@@ -32,45 +32,119 @@ const (
 	serverPollInterval = 100 * time.Millisecond
 )
 
+const defaultGitHubHost = "github.com"
+
 // Compile regex once for performance.
-var prURLPattern = regexp.MustCompile(`^/([^/]+)/([^/]+)/pull/(\d+)(?:/.*)?$`)
+var prShortPattern = regexp.MustCompile(`^([\w.-]+)/([\w.-]+)(?:#|/)(\d+)$`)
 
 func main() {
 	var cfg config
 	flag.StringVar(&cfg.backend, "backend", "local", "Backend server URL (use 'local' to launch local server)")
 	flag.StringVar(&cfg.username, "user", "", "GitHub username to check (defaults to current authenticated user)")
 	flag.BoolVar(&cfg.verbose, "verbose", false, "Enable verbose logging")
+	flag.BoolVar(&cfg.actionsOnly, "actions-only", false, "Print only the assigned actions, one \"user: kind (reason)\" per line")
+	flag.BoolVar(&cfg.inclRequest, "include-request", false,
+		"Wrap the output as {\"request\": ..., \"response\": ...} to capture the exact inputs for bug reports")
+	flag.StringVar(&cfg.output, "output", "", "Write results to this file instead of stdout (\"-\" for stdout)")
+	flag.StringVar(&cfg.format, "format", "json", "Output format: json or yaml")
+	flag.StringVar(&cfg.failOn, "fail-on", "blocking",
+		"When to exit non-zero: blocking, not-ready, failing-tests, or any-action")
+	flag.BoolVar(&cfg.quiet, "quiet", false, "Print nothing on stdout; report the result only through the exit status")
 	flag.BoolVar(&cfg.cache, "cache", true, "Enable caching")
 	flag.BoolVar(&cfg.events, "events", false, "Include full event list in response")
+	flag.StringVar(&cfg.tokenFile, "token-file", "",
+		"Read the GitHub token from this file (takes precedence over GITHUB_TOKEN and gh CLI)")
+	flag.BoolVar(&cfg.noRetry, "no-retry", false, "Fail immediately instead of retrying failed requests")
+	flag.BoolVar(&cfg.insecure, "insecure", false, "Skip TLS certificate verification (local development only)")
+	flag.BoolVar(&cfg.doctor, "doctor", false, "Check DNS, TLS, authentication and backend reachability, then exit")
+	flag.DurationVar(&cfg.serverTimeout, "local-server-timeout", serverStartTimeout,
+		"How long to wait for the local server to compile and start (with --backend=local)")
 	flag.StringVar(&cfg.ref, "ref", "", "Reference time for query (RFC3339 format, e.g., 2025-03-16T06:18:08Z)")
+	flag.StringVar(&cfg.get, "get", "", "Print only the value at a dotted path (e.g., analysis.ready_to_merge)")
+	flag.StringVar(&cfg.githubHost, "github-host", defaultGitHubHost, "GitHub host used to expand owner/repo#123 shorthand")
+	flag.StringVar(&cfg.updatedAfter, "updated-after", "",
+		"In stdin mode, skip PRs last updated before this time (RFC3339 format)")
+	flag.StringVar(&cfg.metricsAddr, "metrics-addr", "",
+		"In stdin mode, serve Prometheus metrics on this address (e.g., :9090) until interrupted")
 	flag.Parse()
 
-	if flag.NArg() != 1 {
+	if flag.NArg() > 1 {
 		flag.Usage()
 		os.Exit(1)
 	}
-	cfg.prURL = flag.Arg(0)
 
-	// Validate PR URL
-	if err := validatePRURL(cfg.prURL); err != nil {
-		fmt.Fprintf(os.Stderr, "error: %v\n", err)
-		os.Exit(1)
+	if cfg.doctor {
+		if flag.NArg() > 0 {
+			fmt.Fprint(os.Stderr, "error: --doctor does not take a PR argument\n")
+			os.Exit(1)
+		}
+		if err := run(cfg); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		return
+	}
+
+	// Without a PR reference, use the PR for the current git branch
+	ref := flag.Arg(0)
+	if ref == "" {
+		detected, err := detectBranchPR()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			flag.Usage()
+			os.Exit(1)
+		}
+		ref = detected
+	}
+
+	// A PR reference of "-" reads PRs from stdin, one per line
+	if ref == "-" {
+		cfg.stdin = true
+	} else {
+		prURL, err := normalizePRRef(ref, cfg.githubHost)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		cfg.prURL = prURL
+
+		// Validate PR URL
+		if err := validatePRURL(cfg.prURL, cfg.githubHost); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
 	}
 
 	if err := run(cfg); err != nil {
 		fmt.Fprintf(os.Stderr, "error: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitCode(err))
 	}
 }
 
 type config struct {
-	backend  string
-	username string
-	prURL    string
-	ref      string
-	verbose  bool
-	cache    bool
-	events   bool
+	backend       string
+	username      string
+	prURL         string
+	ref           string
+	githubHost    string
+	get           string
+	updatedAfter  string
+	metricsAddr   string
+	tokenFile     string
+	failOn        string
+	format        string
+	serverTimeout time.Duration
+	verbose       bool
+	quiet         bool
+	actionsOnly   bool
+	inclRequest   bool
+	output        string
+	cache         bool
+	events        bool
+	noRetry       bool
+	insecure      bool
+	doctor        bool
+	stdin         bool
 }
 
 //nolint:gocognit,gocyclo // Main function handles multiple concerns
@@ -93,12 +167,37 @@ func run(cfg config) error {
 		logger.Printf("using reference time: %s", refTime.Format(time.RFC3339))
 	}
 
+	if cfg.metricsAddr != "" && !cfg.stdin {
+		return errors.New("--metrics-addr is only supported when reading PRs from stdin")
+	}
+
+	var updatedAfter time.Time
+	if cfg.updatedAfter != "" {
+		if !cfg.stdin {
+			return errors.New("--updated-after is only supported when reading PRs from stdin")
+		}
+		var err error
+		updatedAfter, err = time.Parse(time.RFC3339, cfg.updatedAfter)
+		if err != nil {
+			return fmt.Errorf("invalid --updated-after time %q: %w (expected RFC3339 format like 2024-01-01T00:00:00Z)",
+				cfg.updatedAfter, err)
+		}
+	}
+
+	gate, err := gateFor(cfg.failOn)
+	if err != nil {
+		return err
+	}
+	if cfg.format != "json" && cfg.format != "yaml" {
+		return fmt.Errorf("invalid --format %q (expected json or yaml)", cfg.format)
+	}
+
 	// Handle local backend mode
 	var serverCmd *exec.Cmd
 	interrupted := make(chan struct{}) // Signal handler notification
 	local := cfg.backend == "local"
 	if local {
-		port, cmd, err := startLocalServer(logger)
+		port, cmd, err := startLocalServer(logger, cfg.serverTimeout)
 		if err != nil {
 			return fmt.Errorf("starting local server: %w", err)
 		}
@@ -125,14 +224,16 @@ func run(cfg config) error {
 		defer func() {
 			if serverCmd != nil && serverCmd.Process != nil {
 				logger.Print("stopping local server")
-				if err := serverCmd.Process.Signal(syscall.SIGTERM); err != nil {
+				if err := signalProcessGroup(serverCmd, syscall.SIGTERM); err != nil {
 					logger.Printf("failed to send SIGTERM to server: %v", err)
 				}
 				// Don't wait here as the monitor goroutine is already waiting
 			}
 		}()
+	}
 
-		// Set up signal handling
+	// Set up signal handling, needed to stop the local server or the metrics endpoint
+	if local || cfg.metricsAddr != "" {
 		sigs := make(chan os.Signal, 1)
 		signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
 		go func() {
@@ -140,7 +241,7 @@ func run(cfg config) error {
 			logger.Print("received interrupt signal")
 			close(interrupted) // Notify main goroutine
 			if local && serverCmd != nil && serverCmd.Process != nil {
-				if err := serverCmd.Process.Signal(syscall.SIGTERM); err != nil {
+				if err := signalProcessGroup(serverCmd, syscall.SIGTERM); err != nil {
 					logger.Printf("failed to send SIGTERM to server: %v", err)
 				}
 			}
@@ -149,11 +250,33 @@ func run(cfg config) error {
 
 	logger.Printf("starting check for PR: %s, user: %s, backend: %s", cfg.prURL, cfg.username, cfg.backend)
 
-	// Get GitHub token from environment or gh CLI
-	token := os.Getenv("GITHUB_TOKEN")
+	// Get GitHub token from a file, the environment, netrc, or gh CLI
+	var token string
+	if cfg.tokenFile != "" {
+		var err error
+		token, err = readTokenFile(cfg.tokenFile)
+		if err != nil {
+			return err
+		}
+		logger.Printf("read GitHub token from %s", cfg.tokenFile)
+	}
+	if token == "" {
+		token = os.Getenv("GITHUB_TOKEN")
+	}
 	if token == "" {
 		token = os.Getenv("GH_TOKEN")
 	}
+	if token == "" {
+		path := netrcPath()
+		var err error
+		token, err = netrcPassword(path, cfg.githubHost)
+		if err != nil {
+			return err
+		}
+		if token != "" {
+			logger.Printf("read GitHub token from %s", path)
+		}
+	}
 	if token == "" {
 		// Try gh CLI
 		ctx, cancel := context.WithTimeout(context.Background(), userAuthTimeout)
@@ -166,7 +289,7 @@ func run(cfg config) error {
 	}
 	if token == "" {
 		logger.Println("no GitHub token found")
-		if cfg.username == "" {
+		if cfg.username == "" && !cfg.doctor {
 			return errors.New("no GitHub token found and no username specified; " +
 				"to authenticate, run 'gh auth login' or set GITHUB_TOKEN environment variable; " +
 				"alternatively, specify --user=<username> to check a specific user")
@@ -176,7 +299,15 @@ func run(cfg config) error {
 		logger.Println("GitHub token found")
 	}
 
-	client, err := turn.NewClient(cfg.backend)
+	opts := []turn.Option{turn.WithBackend(cfg.backend)}
+	if cfg.noRetry {
+		opts = append(opts, turn.WithoutRetries())
+	}
+	if cfg.insecure {
+		fmt.Fprint(os.Stderr, "warning: TLS certificate verification is disabled\n")
+		opts = append(opts, turn.WithInsecureSkipVerify(true))
+	}
+	client, err := turn.New(opts...)
 	if err != nil {
 		return fmt.Errorf("creating client: %w", err)
 	}
@@ -185,7 +316,7 @@ func run(cfg config) error {
 	}
 	if token != "" {
 		client.SetAuthToken(token)
-		if cfg.username == "" {
+		if cfg.username == "" && !cfg.doctor {
 			ctx, cancel := context.WithTimeout(context.Background(), userAuthTimeout)
 			defer cancel()
 			user, err := client.CurrentUser(ctx)
@@ -205,6 +336,82 @@ func run(cfg config) error {
 		client.IncludeEvents()
 	}
 
+	if cfg.doctor {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() {
+			select {
+			case <-interrupted:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+		d := &doctor{client: client, out: os.Stdout, backend: cfg.backend, hasToken: token != "", insecure: cfg.insecure}
+		return d.run(ctx)
+	}
+
+	var out io.Writer = os.Stdout
+	var outFile *outputFile
+	switch {
+	case cfg.output != "" && cfg.output != "-":
+		outFile, err = createOutput(cfg.output)
+		if err != nil {
+			return err
+		}
+		defer outFile.discard()
+		out = outFile
+	case cfg.quiet:
+		out = io.Discard
+	}
+
+	if cfg.stdin {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() {
+			select {
+			case <-interrupted:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+
+		var checker turn.Checker = client
+		if cfg.metricsAddr != "" {
+			m := &metrics{}
+			checker = &instrumentedChecker{Checker: client, metrics: m}
+			stop, err := serveMetrics(cfg.metricsAddr, m, logger)
+			if err != nil {
+				return err
+			}
+			defer stop()
+		}
+
+		b := &batch{
+			checker:      checker,
+			logger:       logger,
+			out:          out,
+			errOut:       os.Stderr,
+			user:         cfg.username,
+			host:         cfg.githubHost,
+			get:          cfg.get,
+			format:       cfg.format,
+			actionsOnly:  cfg.actionsOnly,
+			refTime:      refTime,
+			updatedAfter: updatedAfter,
+		}
+		err := b.run(ctx, os.Stdin)
+		if outFile != nil && ctx.Err() == nil {
+			if commitErr := outFile.commit(); commitErr != nil {
+				return commitErr
+			}
+		}
+		if cfg.metricsAddr != "" && ctx.Err() == nil {
+			logger.Print("input exhausted, serving metrics until interrupted")
+			<-ctx.Done()
+		}
+		return err
+	}
+
 	// Create a cancellable context for the request
 	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
 	defer cancel()
@@ -243,21 +450,84 @@ func run(cfg config) error {
 		}
 	}
 
-	enc := json.NewEncoder(os.Stdout)
-	enc.SetIndent("", "  ")
-	if err := enc.Encode(result); err != nil {
-		return fmt.Errorf("encoding response: %w", err)
+	var output any = result
+	if cfg.inclRequest {
+		output = exchange{Request: client.CheckRequestFor(cfg.prURL, cfg.username, refTime, turn.CheckOptions{}), Response: result}
 	}
 
-	// Return non-nil error to indicate blocking actions found
-	if blockingActions > 0 {
-		return fmt.Errorf("found %d blocking actions", blockingActions)
+	switch {
+	case cfg.get != "":
+		val, err := resolveField(result, cfg.get)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(out, val)
+	case cfg.actionsOnly:
+		for _, line := range actionLines(result) {
+			fmt.Fprintln(out, line)
+		}
+	case cfg.format == "yaml":
+		if err := writeYAML(out, output); err != nil {
+			return fmt.Errorf("encoding response: %w", err)
+		}
+	default:
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(output); err != nil {
+			return fmt.Errorf("encoding response: %w", err)
+		}
 	}
-	return nil
+
+	if outFile != nil {
+		if err := outFile.commit(); err != nil {
+			return err
+		}
+	}
+
+	// Return non-nil error when the PR fails the --fail-on gate
+	return gate(result)
+}
+
+// detectBranchPR returns the URL of the open PR for the current git branch,
+// as reported by the GitHub CLI.
+func detectBranchPR() (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), userAuthTimeout)
+	defer cancel()
+
+	git := exec.CommandContext(ctx, "git", "rev-parse", "--is-inside-work-tree")
+	if out, err := git.Output(); err != nil || strings.TrimSpace(string(out)) != "true" {
+		return "", errors.New("no PR given and not inside a git working tree")
+	}
+
+	gh := exec.CommandContext(ctx, "gh", "pr", "view", "--json", "url", "--jq", ".url")
+	gh.Stderr = io.Discard
+	out, err := gh.Output()
+	if err != nil {
+		return "", fmt.Errorf("no PR given and no open PR found for the current branch (gh pr view: %w)", err)
+	}
+	prURL := strings.TrimSpace(string(out))
+	if prURL == "" {
+		return "", errors.New("no PR given and no open PR found for the current branch")
+	}
+	return prURL, nil
+}
+
+// readTokenFile reads a GitHub token from path, trimming surrounding whitespace.
+func readTokenFile(path string) (string, error) {
+	data, err := os.ReadFile(path) // #nosec G304 - path is supplied by the user running the tool
+	if err != nil {
+		return "", fmt.Errorf("reading token file: %w", err)
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", fmt.Errorf("token file %s is empty", path)
+	}
+	return token, nil
 }
 
 // startLocalServer starts the turnserver as a subprocess on port 0 and returns the actual port.
-func startLocalServer(logger *log.Logger) (int, *exec.Cmd, error) {
+// It gives up if the server is not accepting connections within timeout.
+func startLocalServer(logger *log.Logger, timeout time.Duration) (int, *exec.Cmd, error) {
 	// Server is expected to be at ../server relative to client
 	sourceDir := "../server"
 	if _, err := os.Stat(filepath.Join(sourceDir, "cmd", "server", "main.go")); err != nil {
@@ -289,6 +559,7 @@ func startLocalServer(logger *log.Logger) (int, *exec.Cmd, error) {
 	// #nosec G204 - port is internally controlled from net.Listen, not user input
 	cmd := exec.CommandContext(context.Background(), "go", "run", "./cmd/server", fmt.Sprintf("--port=%d", port))
 	cmd.Dir = sourceDir
+	setProcessGroup(cmd)
 
 	// Capture server output
 	stdout, err := cmd.StdoutPipe()
@@ -326,7 +597,7 @@ func startLocalServer(logger *log.Logger) (int, *exec.Cmd, error) {
 	go forwardOutput(stderr, "stderr")
 
 	// Wait for server to be ready
-	ctx, cancel := context.WithTimeout(context.Background(), serverStartTimeout)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
 	ticker := time.NewTicker(serverPollInterval)
@@ -336,11 +607,12 @@ func startLocalServer(logger *log.Logger) (int, *exec.Cmd, error) {
 		select {
 		case <-ctx.Done():
 			if cmd.Process != nil {
-				if err := cmd.Process.Kill(); err != nil {
+				if err := signalProcessGroup(cmd, syscall.SIGKILL); err != nil {
 					logger.Printf("failed to kill server process: %v", err)
 				}
 			}
-			return 0, nil, fmt.Errorf("server failed to start within %s", serverStartTimeout)
+			return 0, nil, fmt.Errorf("server failed to start within %s "+
+				"(go run may still be compiling; try a larger --local-server-timeout)", timeout)
 		case <-ticker.C:
 			// Try to connect to the server
 			dialer := &net.Dialer{Timeout: serverPollInterval}
@@ -360,8 +632,28 @@ func startLocalServer(logger *log.Logger) (int, *exec.Cmd, error) {
 	}
 }
 
-// validatePRURL validates that the given URL is a valid GitHub PR URL.
-func validatePRURL(prURL string) error {
+// normalizePRRef expands owner/repo#123 or owner/repo/123 shorthand into a full
+// pull request URL on the given host. Full URLs are returned unchanged.
+func normalizePRRef(ref, host string) (string, error) {
+	if strings.Contains(ref, "://") {
+		return ref, nil
+	}
+	m := prShortPattern.FindStringSubmatch(ref)
+	if m == nil {
+		return "", fmt.Errorf("invalid PR reference %q (expected a URL, owner/repo#123, or owner/repo/123)", ref)
+	}
+	return fmt.Sprintf("https://%s/%s/%s/pull/%s", host, m[1], m[2], m[3]), nil
+}
+
+// exchange pairs a check request with the response it produced, for
+// --include-request.
+type exchange struct {
+	Request  turn.CheckRequest   `json:"request"`
+	Response *turn.CheckResponse `json:"response"`
+}
+
+// validatePRURL validates that the given URL is a valid pull request URL on host.
+func validatePRURL(prURL, host string) error {
 	if prURL == "" {
 		return errors.New("pr URL cannot be empty")
 	}
@@ -375,11 +667,11 @@ func validatePRURL(prURL string) error {
 		return errors.New("url must use http or https scheme")
 	}
 
-	if u.Host != "github.com" && u.Host != "www.github.com" {
-		return errors.New("url must be a GitHub URL")
+	if u.Host != host && u.Host != "www."+host {
+		return fmt.Errorf("url must be a %s URL", host)
 	}
 
-	if !prURLPattern.MatchString(u.Path) {
+	if _, _, _, err := turn.ParsePRURL(prURL); err != nil {
 		return errors.New("url must be a GitHub pull request URL (e.g., https://github.com/owner/repo/pull/123)")
 	}
 
@@ -1,8 +1,16 @@
 package main
 
 import (
+	"encoding/json"
+	"errors"
 	"flag"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/codeGROOVE-dev/turnclient/pkg/turn"
 )
 
 func TestConfig(t *testing.T) {
@@ -87,10 +95,120 @@ func TestValidatePRURL(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := validatePRURL(tt.url)
+			err := validatePRURL(tt.url, defaultGitHubHost)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("validatePRURL() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
 	}
 }
+
+func TestNormalizePRRef(t *testing.T) {
+	tests := []struct {
+		name    string
+		ref     string
+		host    string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "full URL passes through",
+			ref:  "https://github.com/owner/repo/pull/123/files",
+			host: "github.com",
+			want: "https://github.com/owner/repo/pull/123/files",
+		},
+		{
+			name: "hash shorthand",
+			ref:  "owner/repo#123",
+			host: "github.com",
+			want: "https://github.com/owner/repo/pull/123",
+		},
+		{
+			name: "slash shorthand",
+			ref:  "my-org/my.repo/42",
+			host: "github.com",
+			want: "https://github.com/my-org/my.repo/pull/42",
+		},
+		{
+			name: "custom host",
+			ref:  "owner/repo#7",
+			host: "github.example.com",
+			want: "https://github.example.com/owner/repo/pull/7",
+		},
+		{
+			name:    "missing number",
+			ref:     "owner/repo",
+			host:    "github.com",
+			wantErr: true,
+		},
+		{
+			name:    "non-numeric number",
+			ref:     "owner/repo#abc",
+			host:    "github.com",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := normalizePRRef(tt.ref, tt.host)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("normalizePRRef() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("normalizePRRef() = %q, want %q", got, tt.want)
+			}
+			if !tt.wantErr {
+				if err := validatePRURL(got, tt.host); err != nil {
+					t.Errorf("validatePRURL(%q) = %v", got, err)
+				}
+			}
+		})
+	}
+}
+
+func TestReadTokenFile(t *testing.T) {
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "token")
+	if err := os.WriteFile(path, []byte("  ghp_secret\n"), 0o600); err != nil {
+		t.Fatalf("failed to write token file: %v", err)
+	}
+	token, err := readTokenFile(path)
+	if err != nil {
+		t.Fatalf("readTokenFile() failed: %v", err)
+	}
+	if token != "ghp_secret" {
+		t.Errorf("readTokenFile() = %q, want ghp_secret", token)
+	}
+
+	empty := filepath.Join(dir, "empty")
+	if err := os.WriteFile(empty, []byte(" \n\t"), 0o600); err != nil {
+		t.Fatalf("failed to write token file: %v", err)
+	}
+	if _, err := readTokenFile(empty); err == nil || !strings.Contains(err.Error(), "is empty") {
+		t.Errorf("readTokenFile(empty) error = %v, want empty file error", err)
+	}
+
+	if _, err := readTokenFile(filepath.Join(dir, "missing")); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("readTokenFile(missing) error = %v, want fs.ErrNotExist", err)
+	}
+}
+
+func TestExchangeJSON(t *testing.T) {
+	x := exchange{
+		Request:  turn.CheckRequest{URL: "https://github.com/owner/repo/pull/1", User: "alice"},
+		Response: &turn.CheckResponse{Commit: "abc"},
+	}
+	data, err := json.Marshal(x)
+	if err != nil {
+		t.Fatalf("json.Marshal() failed: %v", err)
+	}
+	var got map[string]json.RawMessage
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("json.Unmarshal() failed: %v", err)
+	}
+	if len(got) != 2 || got["request"] == nil || got["response"] == nil {
+		t.Errorf("exchange JSON = %s, want request and response keys only", data)
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/codeGROOVE-dev/turnclient/pkg/turn"
+)
+
+const metricsShutdownTimeout = 5 * time.Second
+
+// latencyBuckets are the upper bounds, in seconds, of the check latency histogram.
+var latencyBuckets = []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// metrics collects check counters and latencies for the Prometheus text format.
+type metrics struct {
+	buckets []uint64 // Per-bucket (non-cumulative) counts, plus a final +Inf bucket
+	sum     float64
+	checks  uint64
+	errors  uint64
+	mu      sync.Mutex
+}
+
+func (m *metrics) observe(d time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.buckets == nil {
+		m.buckets = make([]uint64, len(latencyBuckets)+1)
+	}
+	m.checks++
+	if err != nil {
+		m.errors++
+	}
+	secs := d.Seconds()
+	m.sum += secs
+	i := 0
+	for i < len(latencyBuckets) && secs > latencyBuckets[i] {
+		i++
+	}
+	m.buckets[i]++
+}
+
+// write renders the metrics in the Prometheus text exposition format.
+func (m *metrics) write(w io.Writer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var buf []byte
+	buf = fmt.Appendf(buf, "# HELP turnclient_checks_total Total number of PR checks performed.\n")
+	buf = fmt.Appendf(buf, "# TYPE turnclient_checks_total counter\n")
+	buf = fmt.Appendf(buf, "turnclient_checks_total %d\n", m.checks)
+	buf = fmt.Appendf(buf, "# HELP turnclient_check_errors_total Total number of PR checks that failed.\n")
+	buf = fmt.Appendf(buf, "# TYPE turnclient_check_errors_total counter\n")
+	buf = fmt.Appendf(buf, "turnclient_check_errors_total %d\n", m.errors)
+	buf = fmt.Appendf(buf, "# HELP turnclient_check_duration_seconds Latency of PR checks.\n")
+	buf = fmt.Appendf(buf, "# TYPE turnclient_check_duration_seconds histogram\n")
+	var cumulative uint64
+	for i, le := range latencyBuckets {
+		if m.buckets != nil {
+			cumulative += m.buckets[i]
+		}
+		buf = fmt.Appendf(buf, "turnclient_check_duration_seconds_bucket{le=\"%g\"} %d\n", le, cumulative)
+	}
+	buf = fmt.Appendf(buf, "turnclient_check_duration_seconds_bucket{le=\"+Inf\"} %d\n", m.checks)
+	buf = fmt.Appendf(buf, "turnclient_check_duration_seconds_sum %g\n", m.sum)
+	buf = fmt.Appendf(buf, "turnclient_check_duration_seconds_count %d\n", m.checks)
+
+	_, err := w.Write(buf)
+	return err
+}
+
+// instrumentedChecker records metrics for every check made through it.
+type instrumentedChecker struct {
+	turn.Checker
+
+	metrics *metrics
+}
+
+func (c *instrumentedChecker) Check(ctx context.Context, prURL, user string, updatedAt time.Time) (*turn.CheckResponse, error) {
+	start := time.Now()
+	result, err := c.Checker.Check(ctx, prURL, user, updatedAt)
+	c.metrics.observe(time.Since(start), err)
+	return result, err
+}
+
+// serveMetrics starts an HTTP server exposing m at /metrics on addr.
+// The returned function shuts the server down gracefully.
+func serveMetrics(addr string, m *metrics, logger *log.Logger) (func(), error) {
+	lc := &net.ListenConfig{}
+	ln, err := lc.Listen(context.Background(), "tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("listening for metrics on %s: %w", addr, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /metrics", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		if err := m.write(w); err != nil {
+			logger.Printf("failed to write metrics: %v", err)
+		}
+	})
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+
+	go func() {
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Printf("metrics server failed: %v", err)
+		}
+	}()
+	logger.Printf("serving metrics on %s/metrics", ln.Addr())
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), metricsShutdownTimeout)
+		defer cancel()
+		logger.Print("stopping metrics server")
+		if err := srv.Shutdown(ctx); err != nil {
+			logger.Printf("failed to stop metrics server: %v", err)
+		}
+	}, nil
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/codeGROOVE-dev/turnclient/pkg/turn"
+	"github.com/codeGROOVE-dev/turnclient/pkg/turn/turntest"
+)
+
+func TestMetricsWrite(t *testing.T) {
+	m := &metrics{}
+	m.observe(50*time.Millisecond, nil)
+	m.observe(300*time.Millisecond, nil)
+	m.observe(time.Minute, errors.New("boom"))
+
+	var out strings.Builder
+	if err := m.write(&out); err != nil {
+		t.Fatalf("write() failed: %v", err)
+	}
+
+	for _, want := range []string{
+		"turnclient_checks_total 3\n",
+		"turnclient_check_errors_total 1\n",
+		`turnclient_check_duration_seconds_bucket{le="0.1"} 1` + "\n",
+		`turnclient_check_duration_seconds_bucket{le="0.5"} 2` + "\n",
+		`turnclient_check_duration_seconds_bucket{le="30"} 2` + "\n",
+		`turnclient_check_duration_seconds_bucket{le="+Inf"} 3` + "\n",
+		"turnclient_check_duration_seconds_count 3\n",
+		"# TYPE turnclient_check_duration_seconds histogram\n",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("metrics output missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestInstrumentedChecker(t *testing.T) {
+	prURL := "https://github.com/owner/repo/pull/1"
+	m := &metrics{}
+	c := &instrumentedChecker{
+		Checker: &turntest.FakeClient{Responses: map[string]*turn.CheckResponse{prURL: {}}},
+		metrics: m,
+	}
+
+	ctx := context.Background()
+	if _, err := c.Check(ctx, prURL, "alice", time.Now()); err != nil {
+		t.Fatalf("Check() failed: %v", err)
+	}
+	if _, err := c.Check(ctx, "https://github.com/owner/repo/pull/2", "alice", time.Now()); err == nil {
+		t.Fatal("expected error for unregistered PR")
+	}
+	if m.checks != 2 || m.errors != 1 {
+		t.Errorf("checks = %d, errors = %d; want 2, 1", m.checks, m.errors)
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// netrcPath returns the netrc file to consult: $NETRC if set, otherwise
+// ~/.netrc.
+func netrcPath() string {
+	if p := os.Getenv("NETRC"); p != "" {
+		return p
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".netrc")
+}
+
+// netrcPassword returns the password of the machine entry for host in the
+// netrc file at path. A missing file or entry yields "" and no error. The
+// "default" entry is ignored so that a catch-all credential is never sent
+// to GitHub by accident.
+func netrcPassword(path, host string) (string, error) {
+	if path == "" {
+		return "", nil
+	}
+	data, err := os.ReadFile(path) // #nosec G304 - path is the user's own netrc file
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", nil
+		}
+		return "", fmt.Errorf("reading netrc: %w", err)
+	}
+
+	var (
+		fields   []string
+		inMacro  bool
+		lineScan = bufio.NewScanner(strings.NewReader(string(data)))
+	)
+	for lineScan.Scan() {
+		line := lineScan.Text()
+		// Macro definitions run until the next blank line
+		if inMacro {
+			inMacro = strings.TrimSpace(line) != ""
+			continue
+		}
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		for _, f := range strings.Fields(line) {
+			fields = append(fields, f)
+			if f == "macdef" {
+				inMacro = true
+				break
+			}
+		}
+	}
+	if err := lineScan.Err(); err != nil {
+		return "", fmt.Errorf("reading netrc: %w", err)
+	}
+
+	matched := false
+	for i := 0; i < len(fields); i++ {
+		switch fields[i] {
+		case "machine":
+			if i+1 < len(fields) {
+				i++
+				matched = strings.EqualFold(fields[i], host)
+			}
+		case "default", "macdef":
+			matched = false
+		case "password":
+			if i+1 < len(fields) {
+				i++
+				if matched {
+					return fields[i], nil
+				}
+			}
+		case "login", "account":
+			i++ // skip the value
+		default:
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNetrcPassword(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "netrc")
+	content := `# credentials
+default login anon password catch-all
+
+machine example.com login bob password other-secret
+macdef init
+	machine github.com password from-macro
+
+machine GitHub.com
+	login alice
+	password gh-secret
+`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		host string
+		want string
+	}{
+		{host: "github.com", want: "gh-secret"},
+		{host: "example.com", want: "other-secret"},
+		{host: "ghe.example.org", want: ""},
+	}
+	for _, tt := range tests {
+		got, err := netrcPassword(path, tt.host)
+		if err != nil {
+			t.Fatalf("netrcPassword(%q) failed: %v", tt.host, err)
+		}
+		if got != tt.want {
+			t.Errorf("netrcPassword(%q) = %q, want %q", tt.host, got, tt.want)
+		}
+	}
+
+	got, err := netrcPassword(filepath.Join(t.TempDir(), "missing"), "github.com")
+	if err != nil || got != "" {
+		t.Errorf("netrcPassword(missing) = (%q, %v), want (\"\", nil)", got, err)
+	}
+}
+
+func TestNetrcPath(t *testing.T) {
+	t.Setenv("NETRC", "/custom/netrc")
+	if got := netrcPath(); got != "/custom/netrc" {
+		t.Errorf("netrcPath() = %q, want /custom/netrc", got)
+	}
+}
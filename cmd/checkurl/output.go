@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// outputFile writes --output atomically: data goes to a temporary file in
+// the destination directory, which replaces the destination only on commit,
+// so an interrupted run never leaves a partial file behind.
+type outputFile struct {
+	f    *os.File
+	path string
+	done bool
+}
+
+// createOutput creates the temporary file for path, creating parent
+// directories as needed.
+func createOutput(path string) (*outputFile, error) {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating output directory: %w", err)
+	}
+	f, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return nil, fmt.Errorf("creating output file: %w", err)
+	}
+	return &outputFile{f: f, path: path}, nil
+}
+
+func (o *outputFile) Write(p []byte) (int, error) {
+	return o.f.Write(p)
+}
+
+// commit moves the written data into place. It is a no-op after the first
+// call to commit or discard.
+func (o *outputFile) commit() error {
+	if o.done {
+		return nil
+	}
+	o.done = true
+	err := o.f.Chmod(0o644)
+	if closeErr := o.f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(o.f.Name(), o.path)
+	}
+	if err != nil {
+		_ = os.Remove(o.f.Name()) // Best-effort cleanup
+		return fmt.Errorf("writing output file: %w", err)
+	}
+	return nil
+}
+
+// discard removes the temporary file, leaving any existing destination
+// untouched. It is a no-op after commit.
+func (o *outputFile) discard() {
+	if o.done {
+		return
+	}
+	o.done = true
+	_ = o.f.Close()
+	_ = os.Remove(o.f.Name())
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOutputFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "nested", "out", "result.json")
+
+	o, err := createOutput(path)
+	if err != nil {
+		t.Fatalf("createOutput() failed: %v", err)
+	}
+	if _, err := io.WriteString(o, `{"ok":true}`); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("destination exists before commit (err = %v)", err)
+	}
+	if err := o.commit(); err != nil {
+		t.Fatalf("commit() failed: %v", err)
+	}
+	o.discard() // no-op after commit
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading output: %v", err)
+	}
+	if string(data) != `{"ok":true}` {
+		t.Errorf("output = %q, want {\"ok\":true}", data)
+	}
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatalf("ReadDir() failed: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("output directory has %d entries, want only the result file", len(entries))
+	}
+}
+
+func TestOutputFileDiscard(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "result.json")
+	if err := os.WriteFile(path, []byte("previous"), 0o600); err != nil {
+		t.Fatalf("WriteFile() failed: %v", err)
+	}
+
+	o, err := createOutput(path)
+	if err != nil {
+		t.Fatalf("createOutput() failed: %v", err)
+	}
+	if _, err := io.WriteString(o, "partial"); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+	o.discard()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading output: %v", err)
+	}
+	if string(data) != "previous" {
+		t.Errorf("output = %q, want the previous contents kept", data)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir() failed: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("directory has %d entries, want the temporary file removed", len(entries))
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// yamlPlain matches strings that can be written unquoted: they cannot be
+// mistaken for another YAML type or structure.
+var yamlPlain = regexp.MustCompile(`^[A-Za-z_/][A-Za-z0-9_ ./@+-]*$`)
+
+// writeYAML writes v as a YAML document. It goes through v's JSON encoding,
+// so field names follow the json tags and times render as RFC3339 strings.
+// Map keys are sorted for stable output.
+//
+// This is a minimal encoder for checkurl's output, not a general YAML library.
+func writeYAML(w io.Writer, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if isCollection(doc) {
+		writeYAMLValue(&buf, doc, 0)
+	} else {
+		buf.WriteString(yamlScalar(doc))
+		buf.WriteByte('\n')
+	}
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+// writeYAMLValue writes a non-empty map or slice as a block at indent.
+func writeYAMLValue(buf *bytes.Buffer, v any, indent int) {
+	pad := strings.Repeat("  ", indent)
+	switch v := v.(type) {
+	case map[string]any:
+		for _, k := range slices.Sorted(maps.Keys(v)) {
+			fmt.Fprintf(buf, "%s%s:", pad, yamlScalar(k))
+			writeYAMLChild(buf, v[k], indent+1)
+		}
+	case []any:
+		for _, item := range v {
+			buf.WriteString(pad + "-")
+			if m, ok := item.(map[string]any); ok && len(m) > 0 {
+				// Start the mapping on the dash line, YAML's compact form
+				var sub bytes.Buffer
+				writeYAMLValue(&sub, m, indent+1)
+				buf.WriteByte(' ')
+				buf.Write(bytes.TrimLeft(sub.Bytes(), " "))
+				continue
+			}
+			writeYAMLChild(buf, item, indent+1)
+		}
+	default:
+	}
+}
+
+// writeYAMLChild writes v after a "key:" or "-", inline if it is a scalar or
+// empty collection and as an indented block otherwise.
+func writeYAMLChild(buf *bytes.Buffer, v any, indent int) {
+	if !isCollection(v) {
+		buf.WriteString(" " + yamlScalar(v) + "\n")
+		return
+	}
+	buf.WriteByte('\n')
+	writeYAMLValue(buf, v, indent)
+}
+
+// isCollection reports whether v is a non-empty map or slice.
+func isCollection(v any) bool {
+	switch v := v.(type) {
+	case map[string]any:
+		return len(v) > 0
+	case []any:
+		return len(v) > 0
+	default:
+		return false
+	}
+}
+
+// yamlScalar formats a JSON scalar or empty collection as YAML.
+func yamlScalar(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return strconv.FormatBool(v)
+	case json.Number:
+		return v.String()
+	case string:
+		switch strings.ToLower(v) {
+		case "true", "false", "yes", "no", "on", "off", "null", "y", "n":
+			return strconv.Quote(v)
+		default:
+		}
+		if yamlPlain.MatchString(v) && !strings.HasSuffix(v, " ") {
+			return v
+		}
+		return strconv.Quote(v)
+	case map[string]any:
+		return "{}"
+	case []any:
+		return "[]"
+	default:
+		return strconv.Quote(fmt.Sprint(v))
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/codeGROOVE-dev/turnclient/pkg/turn"
+)
+
+func TestWriteYAML(t *testing.T) {
+	resp := &turn.CheckResponse{
+		Timestamp: time.Date(2025, 3, 16, 12, 0, 0, 0, time.UTC),
+		Analysis: turn.Analysis{
+			NextAction: map[string]turn.Action{
+				"alice": {Kind: turn.ActionReview, Reason: "needs review: see #12", Critical: true},
+			},
+			Tags:               []string{"small", "yes"},
+			UnresolvedComments: 3,
+			StateTransitions: []turn.StateTransition{
+				{FromState: "A", ToState: "B"},
+			},
+		},
+	}
+
+	var sb strings.Builder
+	if err := writeYAML(&sb, resp); err != nil {
+		t.Fatalf("writeYAML() failed: %v", err)
+	}
+	got := sb.String()
+
+	for _, want := range []string{
+		"timestamp: \"2025-03-16T12:00:00Z\"\n",
+		"analysis:\n  approved: false\n",
+		"  next_action:\n    alice:\n      critical: true\n      kind: review\n      reason: \"needs review: see #12\"\n",
+		"  tags:\n    - small\n    - \"yes\"\n",
+		"  state_transitions:\n    - from_state: A\n      last_event_kind: \"\"\n",
+		"  unresolved_comments: 3\n",
+		"  checks:\n    failing: 0\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("YAML output missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestYAMLScalar(t *testing.T) {
+	tests := []struct {
+		in   any
+		want string
+	}{
+		{in: nil, want: "null"},
+		{in: true, want: "true"},
+		{in: "plain text", want: "plain text"},
+		{in: "", want: `""`},
+		{in: "123", want: `"123"`},
+		{in: "No", want: `"No"`},
+		{in: "a: b", want: `"a: b"`},
+		{in: "line\nbreak", want: `"line\nbreak"`},
+		{in: []any{}, want: "[]"},
+		{in: map[string]any{}, want: "{}"},
+	}
+	for _, tt := range tests {
+		if got := yamlScalar(tt.in); got != tt.want {
+			t.Errorf("yamlScalar(%#v) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
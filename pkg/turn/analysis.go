@@ -0,0 +1,176 @@
+package turn
+
+import (
+	"maps"
+	"slices"
+	"time"
+)
+
+const (
+	// urgencyMaxAge caps how much an action's age contributes to its urgency.
+	urgencyMaxAge = 365 * 24 * time.Hour
+	// urgencyCritical is added for critical actions; it exceeds any age-based score.
+	urgencyCritical = 1 << 30
+)
+
+// PrimaryActionFor returns the action assigned to the given user.
+// The second return value is false if the user has no action on this PR.
+func (a *Analysis) PrimaryActionFor(user string) (Action, bool) {
+	action, ok := a.NextAction[user]
+	return action, ok
+}
+
+// ReadyForUser reports whether the given user can act on the PR now, i.e. they
+// have an assigned action that is not merely waiting on tests.
+func (a *Analysis) ReadyForUser(user string) bool {
+	action, ok := a.NextAction[user]
+	return ok && action.Kind != ActionTestsPending
+}
+
+// WaitingOnCI reports whether the PR is waiting on CI rather than on a
+// person: checks are pending or waiting on a deployment rule, and no user
+// has a critical action other than waiting for tests.
+func (a *Analysis) WaitingOnCI() bool {
+	if a.Checks.Pending == 0 && a.Checks.Waiting == 0 {
+		return false
+	}
+	for _, action := range a.NextAction {
+		if action.Critical && action.Kind != ActionTestsPending {
+			return false
+		}
+	}
+	return true
+}
+
+// WaitingOnHuman reports whether some user has an action to take, as opposed
+// to only waiting for tests to finish.
+func (a *Analysis) WaitingOnHuman() bool {
+	for _, action := range a.NextAction {
+		if action.Kind != ActionTestsPending {
+			return true
+		}
+	}
+	return false
+}
+
+// TestsRunning reports whether any checks are still executing.
+func (a *Analysis) TestsRunning() bool {
+	return a.Checks.Pending > 0
+}
+
+// TestsComplete reports whether the PR has checks and all of them have
+// finished, whether they passed or failed.
+func (a *Analysis) TestsComplete() bool {
+	return a.Checks.Total > 0 && a.Checks.Pending == 0 && a.Checks.Waiting == 0
+}
+
+// TestProgress returns the fraction of checks that have finished, from 0 to
+// 1, for progress bars. It returns 0 if the PR has no checks.
+func (a *Analysis) TestProgress() float64 {
+	if a.Checks.Total <= 0 {
+		return 0
+	}
+	return min(float64(a.Checks.Passing+a.Checks.Failing)/float64(a.Checks.Total), 1)
+}
+
+// WaitingOn returns the sorted list of users the PR is waiting on.
+func (a *Analysis) WaitingOn() []string {
+	return slices.Sorted(maps.Keys(a.NextAction))
+}
+
+// ActionsByKind groups the users with an assigned action by the action's
+// kind. Each user list is sorted. It returns an empty map if no actions are
+// assigned.
+func (a *Analysis) ActionsByKind() map[ActionKind][]string {
+	byKind := make(map[ActionKind][]string)
+	for _, user := range a.WaitingOn() {
+		kind := a.NextAction[user].Kind
+		byKind[kind] = append(byKind[kind], user)
+	}
+	return byKind
+}
+
+// HasTag reports whether the analysis carries the given tag.
+func (a *Analysis) HasTag(tag string) bool {
+	return slices.Contains(a.Tags, tag)
+}
+
+// TagList returns a copy of Tags that callers may modify freely.
+func (a *Analysis) TagList() []string {
+	return slices.Clone(a.Tags)
+}
+
+// Urgency returns a score for ranking actions: critical actions always rank
+// above non-critical ones, and within each group older actions rank higher.
+func (a *Action) Urgency() int {
+	u := 0
+	if !a.Since.IsZero() {
+		age := max(min(time.Since(a.Since), urgencyMaxAge), 0)
+		u = int(age / time.Minute)
+	}
+	if a.Critical {
+		u += urgencyCritical
+	}
+	return u
+}
+
+// SLAOverage returns how far the time spent in state exceeds limit, per
+// SecondsInState. The result is negative while the PR is still within the
+// limit; a state with no recorded time counts as zero.
+func (a *Analysis) SLAOverage(state WorkflowState, limit time.Duration) time.Duration {
+	return time.Duration(a.SecondsInState[string(state)])*time.Second - limit
+}
+
+// BreachesSLA reports whether the PR has spent longer than limit in state.
+func (a *Analysis) BreachesSLA(state WorkflowState, limit time.Duration) bool {
+	return a.SLAOverage(state, limit) > 0
+}
+
+// SlowestState returns the state the PR has spent the most time in, per
+// SecondsInState. Ties go to the alphabetically first state name. The third
+// return value is false if no time has been recorded.
+func (a *Analysis) SlowestState() (WorkflowState, time.Duration, bool) {
+	var slowest string
+	best := -1
+	for _, state := range slices.Sorted(maps.Keys(a.SecondsInState)) {
+		if secs := a.SecondsInState[state]; secs > best {
+			slowest, best = state, secs
+		}
+	}
+	if best < 0 {
+		return "", 0, false
+	}
+	return WorkflowState(slowest), time.Duration(best) * time.Second, true
+}
+
+// LatestTransition returns the most recent state transition by Timestamp.
+// The second return value is false if there are no transitions.
+func (a *Analysis) LatestTransition() (StateTransition, bool) {
+	ts := a.sortedTransitions()
+	if len(ts) == 0 {
+		return StateTransition{}, false
+	}
+	return ts[len(ts)-1], true
+}
+
+// TransitionDurations returns how long the PR spent in each FromState,
+// computed by diffing the timestamps of consecutive transitions.
+// Durations for states that were entered more than once are summed.
+func (a *Analysis) TransitionDurations() map[string]time.Duration {
+	ts := a.sortedTransitions()
+	durations := make(map[string]time.Duration)
+	for i := 1; i < len(ts); i++ {
+		durations[ts[i].FromState] += ts[i].Timestamp.Sub(ts[i-1].Timestamp)
+	}
+	return durations
+}
+
+// sortedTransitions returns a copy of StateTransitions ordered by Timestamp.
+// The server is expected to return them in order, but we sort defensively.
+func (a *Analysis) sortedTransitions() []StateTransition {
+	ts := slices.Clone(a.StateTransitions)
+	slices.SortStableFunc(ts, func(x, y StateTransition) int {
+		return x.Timestamp.Compare(y.Timestamp)
+	})
+	return ts
+}
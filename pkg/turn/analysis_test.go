@@ -0,0 +1,310 @@
+package turn
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestLatestTransition(t *testing.T) {
+	base := time.Date(2025, 3, 16, 6, 0, 0, 0, time.UTC)
+
+	t.Run("no transitions", func(t *testing.T) {
+		a := &Analysis{}
+		if _, ok := a.LatestTransition(); ok {
+			t.Error("expected ok = false for empty transitions")
+		}
+	})
+
+	t.Run("out of order", func(t *testing.T) {
+		a := &Analysis{
+			StateTransitions: []StateTransition{
+				{FromState: "B", ToState: "C", Timestamp: base.Add(2 * time.Hour)},
+				{FromState: "A", ToState: "B", Timestamp: base},
+			},
+		}
+		got, ok := a.LatestTransition()
+		if !ok {
+			t.Fatal("expected ok = true")
+		}
+		if got.ToState != "C" {
+			t.Errorf("ToState = %s, want C", got.ToState)
+		}
+		// Original slice must not be reordered
+		if a.StateTransitions[0].ToState != "C" {
+			t.Error("LatestTransition mutated StateTransitions")
+		}
+	})
+}
+
+func TestTransitionDurations(t *testing.T) {
+	base := time.Date(2025, 3, 16, 6, 0, 0, 0, time.UTC)
+	a := &Analysis{
+		StateTransitions: []StateTransition{
+			{FromState: "B", ToState: "C", Timestamp: base.Add(3 * time.Hour)},
+			{FromState: "", ToState: "A", Timestamp: base},
+			{FromState: "A", ToState: "B", Timestamp: base.Add(1 * time.Hour)},
+			{FromState: "C", ToState: "A", Timestamp: base.Add(4 * time.Hour)},
+			{FromState: "A", ToState: "D", Timestamp: base.Add(6 * time.Hour)},
+		},
+	}
+
+	got := a.TransitionDurations()
+	want := map[string]time.Duration{
+		"A": 3 * time.Hour, // 1h + 2h
+		"B": 2 * time.Hour,
+		"C": 1 * time.Hour,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("TransitionDurations() = %v, want %v", got, want)
+	}
+	for state, d := range want {
+		if got[state] != d {
+			t.Errorf("TransitionDurations()[%s] = %v, want %v", state, got[state], d)
+		}
+	}
+
+	if d := (&Analysis{}).TransitionDurations(); len(d) != 0 {
+		t.Errorf("expected empty durations, got %v", d)
+	}
+}
+
+func TestPrimaryActionFor(t *testing.T) {
+	a := &Analysis{
+		NextAction: map[string]Action{
+			"alice": {Kind: ActionReview, Critical: true},
+		},
+	}
+
+	got, ok := a.PrimaryActionFor("alice")
+	if !ok || got.Kind != ActionReview {
+		t.Errorf("PrimaryActionFor(alice) = %v, %v; want review, true", got, ok)
+	}
+	if _, ok := a.PrimaryActionFor("bob"); ok {
+		t.Error("PrimaryActionFor(bob) should return false")
+	}
+	if _, ok := (&Analysis{}).PrimaryActionFor("alice"); ok {
+		t.Error("PrimaryActionFor on empty analysis should return false")
+	}
+}
+
+func TestActionUrgency(t *testing.T) {
+	now := time.Now()
+	oldCritical := Action{Critical: true, Since: now.Add(-48 * time.Hour)}
+	newCritical := Action{Critical: true, Since: now.Add(-time.Hour)}
+	ancient := Action{Since: now.Add(-5 * 365 * 24 * time.Hour)}
+	fresh := Action{Since: now.Add(-time.Minute)}
+	future := Action{Since: now.Add(time.Hour)}
+
+	ranked := []Action{oldCritical, newCritical, ancient, fresh, future}
+	for i := 1; i < len(ranked); i++ {
+		if ranked[i-1].Urgency() < ranked[i].Urgency() {
+			t.Errorf("Urgency()[%d] = %d < Urgency()[%d] = %d", i-1, ranked[i-1].Urgency(), i, ranked[i].Urgency())
+		}
+	}
+	if future.Urgency() != 0 {
+		t.Errorf("future action Urgency() = %d, want 0", future.Urgency())
+	}
+}
+
+func TestReadyForUser(t *testing.T) {
+	a := &Analysis{
+		NextAction: map[string]Action{
+			"carol": {Kind: ActionReview},
+			"alice": {Kind: ActionTestsPending},
+			"bob":   {Kind: ActionFixTests},
+		},
+	}
+
+	tests := []struct {
+		user string
+		want bool
+	}{
+		{"carol", true},
+		{"bob", true},
+		{"alice", false},
+		{"dave", false},
+	}
+	for _, tt := range tests {
+		if got := a.ReadyForUser(tt.user); got != tt.want {
+			t.Errorf("ReadyForUser(%s) = %v, want %v", tt.user, got, tt.want)
+		}
+	}
+
+	want := []string{"alice", "bob", "carol"}
+	if got := a.WaitingOn(); !slices.Equal(got, want) {
+		t.Errorf("WaitingOn() = %v, want %v", got, want)
+	}
+	if got := (&Analysis{}).WaitingOn(); len(got) != 0 {
+		t.Errorf("WaitingOn() on empty analysis = %v, want empty", got)
+	}
+}
+
+func TestActionsByKind(t *testing.T) {
+	a := &Analysis{NextAction: map[string]Action{
+		"carol": {Kind: ActionReview},
+		"alice": {Kind: ActionReview},
+		"bob":   {Kind: ActionFixTests},
+	}}
+	got := a.ActionsByKind()
+	if len(got) != 2 {
+		t.Fatalf("ActionsByKind() = %v, want 2 kinds", got)
+	}
+	if !slices.Equal(got[ActionReview], []string{"alice", "carol"}) {
+		t.Errorf("reviewers = %v, want [alice carol]", got[ActionReview])
+	}
+	if !slices.Equal(got[ActionFixTests], []string{"bob"}) {
+		t.Errorf("fix_tests = %v, want [bob]", got[ActionFixTests])
+	}
+
+	empty := (&Analysis{}).ActionsByKind()
+	if empty == nil || len(empty) != 0 {
+		t.Errorf("ActionsByKind() with nil actions = %v, want empty non-nil map", empty)
+	}
+}
+
+func TestTags(t *testing.T) {
+	a := &Analysis{Tags: []string{"has_approval", "draft"}}
+	if !a.HasTag("has_approval") || a.HasTag("missing") {
+		t.Errorf("HasTag() mismatch for tags %v", a.Tags)
+	}
+
+	tags := a.TagList()
+	tags[0] = "changed"
+	if a.Tags[0] != "has_approval" {
+		t.Error("modifying TagList() result changed the analysis")
+	}
+
+	if (&Analysis{}).HasTag("") {
+		t.Error("HasTag() on empty tags should be false")
+	}
+}
+
+func TestSLA(t *testing.T) {
+	a := &Analysis{SecondsInState: map[string]int{
+		string(StateAssignedWaitingForReview): int((30 * time.Hour).Seconds()),
+	}}
+
+	if !a.BreachesSLA(StateAssignedWaitingForReview, 24*time.Hour) {
+		t.Error("BreachesSLA(24h) = false after 30h in state, want true")
+	}
+	if got := a.SLAOverage(StateAssignedWaitingForReview, 24*time.Hour); got != 6*time.Hour {
+		t.Errorf("SLAOverage(24h) = %v, want 6h", got)
+	}
+	if got := a.SLAOverage(StateAssignedWaitingForReview, 48*time.Hour); got != -18*time.Hour {
+		t.Errorf("SLAOverage(48h) = %v, want -18h", got)
+	}
+	if a.BreachesSLA(StateAssignedWaitingForReview, 30*time.Hour) {
+		t.Error("BreachesSLA() at exactly the limit should be false")
+	}
+	if a.BreachesSLA(StateInDraft, time.Hour) {
+		t.Error("BreachesSLA() for a state with no recorded time should be false")
+	}
+}
+
+func TestSlowestState(t *testing.T) {
+	tests := []struct {
+		name      string
+		seconds   map[string]int
+		wantState WorkflowState
+		wantDur   time.Duration
+		wantOK    bool
+	}{
+		{name: "empty"},
+		{
+			name:      "single maximum",
+			seconds:   map[string]int{string(StateAssignedWaitingForReview): 7200, string(StatePublishedWaitingForTests): 600},
+			wantState: StateAssignedWaitingForReview,
+			wantDur:   2 * time.Hour,
+			wantOK:    true,
+		},
+		{
+			name:      "tie broken by name",
+			seconds:   map[string]int{string(StateTestedWaitingForFixes): 60, string(StateApprovedWaitingForMerge): 60},
+			wantState: StateApprovedWaitingForMerge,
+			wantDur:   time.Minute,
+			wantOK:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := Analysis{SecondsInState: tt.seconds}
+			state, dur, ok := a.SlowestState()
+			if state != tt.wantState || dur != tt.wantDur || ok != tt.wantOK {
+				t.Errorf("SlowestState() = (%q, %v, %v), want (%q, %v, %v)", state, dur, ok, tt.wantState, tt.wantDur, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestWaitingOnCIAndHuman(t *testing.T) {
+	tests := []struct {
+		name      string
+		analysis  Analysis
+		wantCI    bool
+		wantHuman bool
+	}{
+		{name: "idle"},
+		{
+			name:     "pending checks only",
+			analysis: Analysis{Checks: Checks{Pending: 2}, NextAction: map[string]Action{"alice": {Kind: ActionTestsPending, Critical: true}}},
+			wantCI:   true,
+		},
+		{
+			name:      "deployment wait with a non-critical review",
+			analysis:  Analysis{Checks: Checks{Waiting: 1}, NextAction: map[string]Action{"bob": {Kind: ActionReview}}},
+			wantCI:    true,
+			wantHuman: true,
+		},
+		{
+			name:      "pending checks but a critical human action",
+			analysis:  Analysis{Checks: Checks{Pending: 1}, NextAction: map[string]Action{"bob": {Kind: ActionFixConflict, Critical: true}}},
+			wantHuman: true,
+		},
+		{
+			name:      "no checks running",
+			analysis:  Analysis{NextAction: map[string]Action{"bob": {Kind: ActionReview, Critical: true}}},
+			wantHuman: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.analysis.WaitingOnCI(); got != tt.wantCI {
+				t.Errorf("WaitingOnCI() = %v, want %v", got, tt.wantCI)
+			}
+			if got := tt.analysis.WaitingOnHuman(); got != tt.wantHuman {
+				t.Errorf("WaitingOnHuman() = %v, want %v", got, tt.wantHuman)
+			}
+		})
+	}
+}
+
+func TestTestProgress(t *testing.T) {
+	tests := []struct {
+		name         string
+		checks       Checks
+		wantRunning  bool
+		wantComplete bool
+		wantProgress float64
+	}{
+		{name: "no checks"},
+		{name: "running", checks: Checks{Total: 4, Passing: 1, Failing: 1, Pending: 2}, wantRunning: true, wantProgress: 0.5},
+		{name: "waiting on deployment", checks: Checks{Total: 2, Passing: 1, Waiting: 1}, wantProgress: 0.5},
+		{name: "complete", checks: Checks{Total: 3, Passing: 2, Failing: 1}, wantComplete: true, wantProgress: 1},
+		{name: "inconsistent counts", checks: Checks{Total: 1, Passing: 2}, wantComplete: true, wantProgress: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &Analysis{Checks: tt.checks}
+			if got := a.TestsRunning(); got != tt.wantRunning {
+				t.Errorf("TestsRunning() = %v, want %v", got, tt.wantRunning)
+			}
+			if got := a.TestsComplete(); got != tt.wantComplete {
+				t.Errorf("TestsComplete() = %v, want %v", got, tt.wantComplete)
+			}
+			if got := a.TestProgress(); got != tt.wantProgress {
+				t.Errorf("TestProgress() = %v, want %v", got, tt.wantProgress)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package turn
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// batchConcurrency caps the number of checks BatchCheck runs at once.
+const batchConcurrency = 8
+
+// BatchCheck runs CheckWithOptions for each request concurrently, passing
+// along its Commit, Metadata and IncludeEvents. The returned slices are
+// parallel to reqs: results[i] is set when errs[i] is nil.
+//
+// If ctx is done before the batch finishes, the results gathered so far are
+// kept and every entry that had not completed gets ctx's error, typically
+// context.DeadlineExceeded.
+func (c *Client) BatchCheck(ctx context.Context, reqs []CheckRequest) (results []*CheckResponse, errs []error) {
+	results = make([]*CheckResponse, len(reqs))
+	errs = make([]error, len(reqs))
+
+	work := make(chan int)
+	var wg sync.WaitGroup
+	for range min(batchConcurrency, len(reqs)) {
+		wg.Go(func() {
+			for i := range work {
+				r := reqs[i]
+				results[i], errs[i] = c.CheckWithOptions(ctx, r.URL, r.User, r.UpdatedAt, r.options())
+			}
+		})
+	}
+
+	next := 0
+feed:
+	for ; next < len(reqs); next++ {
+		select {
+		case work <- next:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(work)
+	wg.Wait()
+
+	// Entries never started, or cut short by ctx, report the context error
+	if err := ctx.Err(); err != nil {
+		for i := range reqs {
+			if i >= next || errors.Is(errs[i], context.Canceled) || errors.Is(errs[i], context.DeadlineExceeded) {
+				results[i], errs[i] = nil, err
+			}
+		}
+	}
+	return results, errs
+}
+
+// Prefetch starts checking reqs in the background so that later Check calls
+// for the same PRs are served from the client's cache. It returns
+// immediately. Failures are logged and otherwise ignored, and only responses
+// the backend marks cacheable are kept. Cancelling ctx stops the prefetch.
+//
+// Prefetch does nothing if caching is disabled with WithNoCache.
+func (c *Client) Prefetch(ctx context.Context, reqs []CheckRequest) {
+	if c.noCache {
+		c.logf("", "prefetch skipped: caching is disabled")
+		return
+	}
+	reqs = slices.Clone(reqs)
+	go func() {
+		_, errs := c.BatchCheck(ctx, reqs)
+		for i, err := range errs {
+			if err != nil {
+				c.logf("", "prefetch of %s failed: %v", truncate(reqs[i].URL, logMaxLength), err)
+			}
+		}
+	}()
+}
+
+// CheckAnyBlocking checks reqs concurrently on behalf of user and reports
+// whether any PR has an action assigned to user, returning that PR's
+// response. It returns as soon as one is found, cancelling the checks still
+// in flight; the User field of each request is ignored.
+//
+// If no PR is blocked on user, the result is false along with any check
+// failures joined into one error, since a failed check may have hidden a
+// blocking PR.
+func (c *Client) CheckAnyBlocking(ctx context.Context, reqs []CheckRequest, user string) (bool, *CheckResponse, error) {
+	if user == "" {
+		return false, nil, errors.New("user cannot be empty")
+	}
+	parent := ctx
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu      sync.Mutex
+		blocked *CheckResponse
+		errs    = make([]error, len(reqs))
+	)
+	work := make(chan int)
+	var wg sync.WaitGroup
+	for range min(batchConcurrency, len(reqs)) {
+		wg.Go(func() {
+			for i := range work {
+				r := reqs[i]
+				resp, err := c.CheckWithOptions(ctx, r.URL, user, r.UpdatedAt, r.options())
+				if err != nil {
+					errs[i] = fmt.Errorf("%s: %w", truncate(r.URL, logMaxLength), err)
+					continue
+				}
+				if _, ok := resp.Analysis.PrimaryActionFor(user); ok {
+					mu.Lock()
+					if blocked == nil {
+						blocked = resp
+						cancel()
+					}
+					mu.Unlock()
+				}
+			}
+		})
+	}
+
+feed:
+	for i := range reqs {
+		select {
+		case work <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(work)
+	wg.Wait()
+
+	if blocked != nil {
+		return true, blocked, nil
+	}
+	if err := parent.Err(); err != nil {
+		return false, nil, err
+	}
+	return false, nil, errors.Join(errs...)
+}
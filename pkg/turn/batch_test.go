@@ -0,0 +1,254 @@
+package turn
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBatchCheckPartialResults(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req CheckRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+			return
+		}
+		switch {
+		case strings.HasSuffix(req.URL, "/slow"):
+			select {
+			case <-r.Context().Done():
+			case <-time.After(5 * time.Second):
+			}
+			return
+		case strings.HasSuffix(req.URL, "/404"):
+			w.WriteHeader(http.StatusNotFound)
+			return
+		default:
+		}
+		if err := json.NewEncoder(w).Encode(CheckResponse{Commit: req.URL}); err != nil {
+			t.Errorf("failed to encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	client, err := New(WithBackend(server.URL), WithoutRetries())
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+
+	now := time.Now()
+	reqs := []CheckRequest{
+		{URL: "https://github.com/owner/repo/pull/1", User: "alice", UpdatedAt: now},
+		{URL: "https://github.com/owner/repo/pull/2/slow", User: "alice", UpdatedAt: now},
+		{URL: "https://github.com/owner/repo/pull/3/404", User: "alice", UpdatedAt: now},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	results, errs := client.BatchCheck(ctx, reqs)
+
+	if errs[0] != nil || results[0] == nil || results[0].Commit != reqs[0].URL {
+		t.Errorf("entry 0 = (%v, %v), want its completed response", results[0], errs[0])
+	}
+	if !errors.Is(errs[1], context.DeadlineExceeded) || results[1] != nil {
+		t.Errorf("entry 1 = (%v, %v), want context.DeadlineExceeded", results[1], errs[1])
+	}
+	var apiErr *APIError
+	if !errors.As(errs[2], &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("entry 2 error = %v, want its own 404", errs[2])
+	}
+}
+
+func TestBatchCheckCanceledBeforeStart(t *testing.T) {
+	client, err := New(WithBackend("http://127.0.0.1:1"))
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	reqs := make([]CheckRequest, 20)
+	for i := range reqs {
+		reqs[i] = CheckRequest{URL: "https://github.com/owner/repo/pull/1", User: "alice", UpdatedAt: time.Now()}
+	}
+	_, errs := client.BatchCheck(ctx, reqs)
+	for i, err := range errs {
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("entry %d error = %v, want context.Canceled", i, err)
+		}
+	}
+}
+
+func TestPrefetch(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Cache-Control", "max-age=60")
+		if err := json.NewEncoder(w).Encode(CheckResponse{Commit: "warm"}); err != nil {
+			t.Errorf("failed to encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	client, err := New(WithBackend(server.URL))
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+
+	updatedAt := time.Now()
+	reqs := []CheckRequest{
+		{URL: "https://github.com/owner/repo/pull/1", User: "alice", UpdatedAt: updatedAt},
+		{URL: "https://github.com/owner/repo/pull/2", User: "alice", UpdatedAt: updatedAt},
+	}
+	client.Prefetch(context.Background(), reqs)
+
+	// Wait for the background checks to land in the cache
+	deadline := time.Now().Add(2 * time.Second)
+	for _, r := range reqs {
+		for time.Now().Before(deadline) {
+			if _, ok := client.cache.load(cacheKey(r), time.Now()); ok {
+				break
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	for _, r := range reqs {
+		if _, err := client.Check(context.Background(), r.URL, r.User, r.UpdatedAt); err != nil {
+			t.Fatalf("Check() failed: %v", err)
+		}
+	}
+	if calls.Load() != 2 {
+		t.Errorf("made %d calls, want 2 (checks after prefetch should hit the cache)", calls.Load())
+	}
+}
+
+func TestPrefetchWithOptions(t *testing.T) {
+	var calls atomic.Int32
+	var got atomic.Pointer[CheckRequest]
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		var req CheckRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		got.Store(&req)
+		w.Header().Set("Cache-Control", "max-age=60")
+		if err := json.NewEncoder(w).Encode(CheckResponse{Commit: "warm"}); err != nil {
+			t.Errorf("failed to encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	client, err := New(WithBackend(server.URL))
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+
+	const prURL = "https://github.com/owner/repo/pull/1"
+	updatedAt := time.Now()
+	opts := CheckOptions{Commit: "abc123", Metadata: map[string]string{"team": "infra"}, IncludeEvents: true}
+	client.Prefetch(context.Background(), []CheckRequest{{
+		URL: prURL, User: "alice", UpdatedAt: updatedAt,
+		Commit: opts.Commit, Metadata: opts.Metadata, IncludeEvents: opts.IncludeEvents,
+	}})
+
+	key := cacheKey(client.CheckRequestFor(prURL, "alice", updatedAt, opts))
+	deadline := time.Now().Add(2 * time.Second)
+	for _, ok := client.cache.lookup(key); !ok && time.Now().Before(deadline); _, ok = client.cache.lookup(key) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if _, err := client.CheckWithOptions(context.Background(), prURL, "alice", updatedAt, opts); err != nil {
+		t.Fatalf("CheckWithOptions() failed: %v", err)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("made %d calls, want 1 (CheckWithOptions after prefetch should hit the cache)", calls.Load())
+	}
+	req := got.Load()
+	if req == nil {
+		t.Fatal("server saw no request")
+	}
+	if req.Commit != opts.Commit || req.Metadata["team"] != "infra" || !req.IncludeEvents {
+		t.Errorf("prefetch sent commit=%q metadata=%v include_events=%v, want the request's options",
+			req.Commit, req.Metadata, req.IncludeEvents)
+	}
+}
+
+func TestCheckAnyBlocking(t *testing.T) {
+	slowCanceled := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req CheckRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+			return
+		}
+		resp := CheckResponse{Commit: req.URL, Analysis: Analysis{NextAction: map[string]Action{}}}
+		switch {
+		case strings.HasSuffix(req.URL, "/slow"):
+			select {
+			case <-r.Context().Done():
+				close(slowCanceled)
+			case <-time.After(5 * time.Second):
+			}
+			return
+		case strings.HasSuffix(req.URL, "/404"):
+			w.WriteHeader(http.StatusNotFound)
+			return
+		case strings.HasSuffix(req.URL, "/blocked"):
+			resp.Analysis.NextAction[req.User] = Action{Kind: ActionReview}
+		default:
+		}
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			t.Errorf("failed to encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	client, err := New(WithBackend(server.URL), WithoutRetries())
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	now := time.Now()
+	ctx := context.Background()
+
+	reqs := []CheckRequest{
+		{URL: "https://github.com/owner/repo/pull/1/slow", UpdatedAt: now},
+		{URL: "https://github.com/owner/repo/pull/2/blocked", UpdatedAt: now},
+	}
+	start := time.Now()
+	blocked, resp, err := client.CheckAnyBlocking(ctx, reqs, "alice")
+	if err != nil || !blocked {
+		t.Fatalf("CheckAnyBlocking() = %v, %v, want true, nil", blocked, err)
+	}
+	if resp.Commit != reqs[1].URL {
+		t.Errorf("response is for %q, want %q", resp.Commit, reqs[1].URL)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("CheckAnyBlocking() took %v, want it to stop at the first blocking PR", elapsed)
+	}
+	select {
+	case <-slowCanceled:
+	case <-time.After(2 * time.Second):
+		t.Error("the unfinished check was not cancelled")
+	}
+
+	reqs = []CheckRequest{
+		{URL: "https://github.com/owner/repo/pull/3", UpdatedAt: now},
+		{URL: "https://github.com/owner/repo/pull/4/404", UpdatedAt: now},
+	}
+	blocked, resp, err = client.CheckAnyBlocking(ctx, reqs, "alice")
+	if blocked || resp != nil {
+		t.Errorf("CheckAnyBlocking() = %v, %v, want false, nil", blocked, resp)
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || !strings.Contains(err.Error(), "pull/4/404") {
+		t.Errorf("CheckAnyBlocking() error = %v, want the 404 for pull/4", err)
+	}
+}
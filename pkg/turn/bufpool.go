@@ -0,0 +1,45 @@
+package turn
+
+import (
+	"bytes"
+	"encoding/json"
+	"sync"
+)
+
+// maxPooledBuffer is the largest buffer returned to the pool; bigger ones,
+// such as those from a large CheckStream batch, are left to the GC so one
+// outlier does not pin its memory for the life of the process.
+const maxPooledBuffer = 64 * 1024
+
+// bufPool holds JSON-encoding scratch buffers shared by all clients.
+var bufPool = sync.Pool{
+	New: func() any { return new(bytes.Buffer) },
+}
+
+// getBuffer returns an empty buffer from the pool.
+func getBuffer() *bytes.Buffer {
+	return bufPool.Get().(*bytes.Buffer) //nolint:forcetypeassert // pool only holds *bytes.Buffer
+}
+
+// putBuffer resets b and returns it to the pool. The caller must not use b,
+// or any request built on it, afterwards.
+func putBuffer(b *bytes.Buffer) {
+	if b.Cap() > maxPooledBuffer {
+		return
+	}
+	b.Reset()
+	bufPool.Put(b)
+}
+
+// encodeJSON encodes v in a pooled scratch buffer and returns a copy of the
+// result for the request body. The pooled buffer is never handed to a
+// request: the transport may still read or close a body after RoundTrip
+// returns, and GetBody replays it on retries.
+func encodeJSON(v any) ([]byte, error) {
+	buf := getBuffer()
+	defer putBuffer(buf)
+	if err := json.NewEncoder(buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.Clone(buf.Bytes()), nil
+}
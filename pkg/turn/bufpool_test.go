@@ -0,0 +1,107 @@
+package turn
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPutBuffer(t *testing.T) {
+	buf := getBuffer()
+	buf.WriteString("leftover")
+	putBuffer(buf)
+
+	if got := getBuffer(); got.Len() != 0 {
+		t.Errorf("getBuffer() returned %d leftover bytes", got.Len())
+	}
+
+	big := getBuffer()
+	big.Grow(2 * maxPooledBuffer)
+	putBuffer(big) // dropped rather than pooled; must not panic
+}
+
+func TestCheckReusesBuffers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req CheckRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(CheckResponse{URL: req.URL, Commit: req.User}); err != nil {
+			t.Errorf("failed to write response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	client, err := New(WithBackend(server.URL), WithNoCache(true))
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+
+	// Alternate long and short bodies so a buffer that was not reset would
+	// leave trailing bytes behind.
+	for i := range 10 {
+		user := "u"
+		if i%2 == 0 {
+			user = fmt.Sprintf("a-much-longer-user-name-%d", i)
+		}
+		got, err := client.Check(context.Background(), "https://github.com/owner/repo/pull/1", user, time.Now())
+		if err != nil {
+			t.Fatalf("Check() #%d failed: %v", i, err)
+		}
+		if got.Commit != user {
+			t.Errorf("Check() #%d: server saw user %q, want %q", i, got.Commit, user)
+		}
+	}
+}
+
+func TestRequestBodyOutlivesCheck(t *testing.T) {
+	// A transport may read a request body after RoundTrip returns, e.g. when
+	// the server answers before the upload finishes. Keep the first request
+	// unread until another check has encoded its own body.
+	var first *http.Request
+	client, err := New(WithNoCache(true))
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	client.httpClient.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if first == nil {
+			first = req
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(`{}`)),
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Request:    req,
+		}, nil
+	})
+
+	for _, user := range []string{"alice", "a-much-longer-user-name"} {
+		if _, err := client.Check(context.Background(), "https://github.com/owner/repo/pull/1", user, time.Now()); err != nil {
+			t.Fatalf("Check() as %s failed: %v", user, err)
+		}
+	}
+
+	for name, open := range map[string]func() (io.ReadCloser, error){
+		"Body":    func() (io.ReadCloser, error) { return first.Body, nil },
+		"GetBody": first.GetBody,
+	} {
+		body, err := open()
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		var req CheckRequest
+		if err := json.NewDecoder(body).Decode(&req); err != nil {
+			t.Fatalf("%s: decode first request: %v", name, err)
+		}
+		if req.User != "alice" {
+			t.Errorf("%s: first request user = %q after a later check, want alice", name, req.User)
+		}
+	}
+}
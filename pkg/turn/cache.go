@@ -0,0 +1,178 @@
+package turn
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/codeGROOVE-dev/prx/pkg/prx"
+)
+
+// maxCacheEntries bounds the number of cached responses per client.
+const maxCacheEntries = 1000
+
+// responseCache holds check responses for as long as the backend's
+// Cache-Control or Expires headers allow. The zero value is ready to use.
+type responseCache struct {
+	entries map[string]cacheEntry
+	mu      sync.Mutex
+}
+
+type cacheEntry struct {
+	expires time.Time
+	resp    *CheckResponse
+}
+
+// cacheKey identifies a check by every input that affects its result.
+func cacheKey(req CheckRequest) string {
+	key := req.URL + "\x00" + req.User + "\x00" + req.UpdatedAt.Format(time.RFC3339Nano) + "\x00" + strconv.FormatBool(req.IncludeEvents) + "\x00" + req.Commit
+	for _, k := range slices.Sorted(maps.Keys(req.Metadata)) {
+		key += "\x00" + k + "=" + req.Metadata[k]
+	}
+	return key
+}
+
+// tokenCacheKey scopes key to a token set by WithContextToken, so callers
+// sharing a client with their own tokens never see each other's cached
+// responses. Only a hash of the token is kept.
+func tokenCacheKey(key, token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return key + "\x00" + hex.EncodeToString(sum[:])
+}
+
+// load returns a copy of the cached response for key if it has not expired.
+func (rc *responseCache) load(key string, now time.Time) (*CheckResponse, bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	e, ok := rc.entries[key]
+	if !ok || !now.Before(e.expires) {
+		return nil, false
+	}
+	return cloneResponse(e.resp), true
+}
+
+// lookup returns a copy of the cached response for key even if it has
+// expired, for revalidation with If-Modified-Since. Expired entries are kept
+// until the cache fills up for this reason.
+func (rc *responseCache) lookup(key string) (*CheckResponse, bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	e, ok := rc.entries[key]
+	if !ok {
+		return nil, false
+	}
+	return cloneResponse(e.resp), true
+}
+
+// store caches a copy of resp until expires. When the cache is full, entries
+// expired as of now are purged first; if it is still full the response is not
+// cached.
+func (rc *responseCache) store(key string, resp *CheckResponse, now, expires time.Time) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if rc.entries == nil {
+		rc.entries = make(map[string]cacheEntry)
+	}
+	if len(rc.entries) >= maxCacheEntries {
+		for k, e := range rc.entries {
+			if !now.Before(e.expires) {
+				delete(rc.entries, k)
+			}
+		}
+		if len(rc.entries) >= maxCacheEntries {
+			return
+		}
+	}
+	rc.entries[key] = cacheEntry{resp: cloneResponse(resp), expires: expires}
+}
+
+// cloneResponse deep-copies r, so callers may modify a cached response's
+// maps and slices without affecting the cache or each other.
+func cloneResponse(r *CheckResponse) *CheckResponse {
+	cp := *r
+	cp.Events = slices.Clone(r.Events)
+
+	pr := &cp.PullRequest
+	pr.ClosedAt = clonePtr(pr.ClosedAt)
+	pr.MergedAt = clonePtr(pr.MergedAt)
+	pr.ApprovalSummary = clonePtr(pr.ApprovalSummary)
+	pr.Mergeable = clonePtr(pr.Mergeable)
+	if cs := pr.CheckSummary; cs != nil {
+		pr.CheckSummary = &prx.CheckSummary{
+			Success:   maps.Clone(cs.Success),
+			Failing:   maps.Clone(cs.Failing),
+			Pending:   maps.Clone(cs.Pending),
+			Cancelled: maps.Clone(cs.Cancelled),
+			Skipped:   maps.Clone(cs.Skipped),
+			Stale:     maps.Clone(cs.Stale),
+			Neutral:   maps.Clone(cs.Neutral),
+		}
+	}
+	pr.Assignees = slices.Clone(pr.Assignees)
+	pr.Labels = slices.Clone(pr.Labels)
+	pr.Commits = slices.Clone(pr.Commits)
+	pr.Reviewers = maps.Clone(pr.Reviewers)
+	pr.ParticipantAccess = maps.Clone(pr.ParticipantAccess)
+
+	a := &cp.Analysis
+	a.NextAction = maps.Clone(a.NextAction)
+	a.SecondsInState = maps.Clone(a.SecondsInState)
+	a.Tags = slices.Clone(a.Tags)
+	a.StateTransitions = slices.Clone(a.StateTransitions)
+	return &cp
+}
+
+// clonePtr returns a pointer to a copy of *p, or nil if p is nil.
+func clonePtr[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	v := *p
+	return &v
+}
+
+// cacheTTL returns how long a response may be cached according to its
+// Cache-Control max-age (less any Age) or, failing that, its Expires header.
+// It returns zero if the response must not be cached.
+func cacheTTL(h http.Header, now time.Time) time.Duration {
+	cc := strings.ToLower(h.Get("Cache-Control"))
+	maxAge := -1
+	for directive := range strings.SplitSeq(cc, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		switch name {
+		case "no-store", "no-cache":
+			return 0
+		case "max-age":
+			if n, err := strconv.Atoi(strings.Trim(value, `"`)); err == nil {
+				maxAge = n
+			}
+		default:
+		}
+	}
+
+	if maxAge >= 0 {
+		ttl := time.Duration(maxAge) * time.Second
+		if age, err := strconv.Atoi(h.Get("Age")); err == nil && age > 0 {
+			ttl -= time.Duration(age) * time.Second
+		}
+		return max(ttl, 0)
+	}
+
+	if exp := h.Get("Expires"); exp != "" {
+		t, err := http.ParseTime(exp)
+		if err != nil {
+			return 0 // Invalid Expires means already expired
+		}
+		return max(t.Sub(now), 0)
+	}
+	return 0
+}
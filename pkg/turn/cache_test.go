@@ -0,0 +1,224 @@
+package turn
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCacheTTL(t *testing.T) {
+	now := time.Date(2025, 3, 16, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		header http.Header
+		want   time.Duration
+	}{
+		{name: "no headers", header: http.Header{}, want: 0},
+		{name: "max-age", header: http.Header{"Cache-Control": {"public, max-age=60"}}, want: time.Minute},
+		{name: "max-age less age", header: http.Header{"Cache-Control": {"max-age=60"}, "Age": {"45"}}, want: 15 * time.Second},
+		{name: "age exceeds max-age", header: http.Header{"Cache-Control": {"max-age=60"}, "Age": {"90"}}, want: 0},
+		{name: "no-store wins", header: http.Header{"Cache-Control": {"max-age=60, no-store"}}, want: 0},
+		{name: "no-cache", header: http.Header{"Cache-Control": {"no-cache"}}, want: 0},
+		{
+			name:   "expires",
+			header: http.Header{"Expires": {now.Add(2 * time.Minute).Format(http.TimeFormat)}},
+			want:   2 * time.Minute,
+		},
+		{
+			name: "max-age overrides expires",
+			header: http.Header{
+				"Cache-Control": {"max-age=10"},
+				"Expires":       {now.Add(time.Hour).Format(http.TimeFormat)},
+			},
+			want: 10 * time.Second,
+		},
+		{name: "expires in past", header: http.Header{"Expires": {now.Add(-time.Hour).Format(http.TimeFormat)}}, want: 0},
+		{name: "invalid expires", header: http.Header{"Expires": {"0"}}, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cacheTTL(tt.header, now); got != tt.want {
+				t.Errorf("cacheTTL() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckHonorsCacheControl(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Cache-Control", "max-age=60")
+		if err := json.NewEncoder(w).Encode(CheckResponse{Commit: "abc123"}); err != nil {
+			t.Errorf("failed to encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	prURL := "https://github.com/owner/repo/pull/123"
+	updatedAt := time.Now()
+	ctx := context.Background()
+
+	client, err := New(WithBackend(server.URL))
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	for range 3 {
+		if _, err := client.Check(ctx, prURL, "alice", updatedAt); err != nil {
+			t.Fatalf("Check() failed: %v", err)
+		}
+	}
+	if calls.Load() != 1 {
+		t.Errorf("made %d calls, want 1 (cached)", calls.Load())
+	}
+
+	// A different user or updatedAt is a different cache entry
+	if _, err := client.Check(ctx, prURL, "bob", updatedAt); err != nil {
+		t.Fatalf("Check() failed: %v", err)
+	}
+	if _, err := client.Check(ctx, prURL, "alice", updatedAt.Add(time.Second)); err != nil {
+		t.Fatalf("Check() failed: %v", err)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("made %d calls, want 3", calls.Load())
+	}
+
+	// noCache skips both serving and storing
+	calls.Store(0)
+	client, err = New(WithBackend(server.URL), WithNoCache(true))
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	for range 2 {
+		if _, err := client.Check(ctx, prURL, "alice", updatedAt); err != nil {
+			t.Fatalf("Check() failed: %v", err)
+		}
+	}
+	if calls.Load() != 2 || len(client.cache.entries) != 0 {
+		t.Errorf("made %d calls with %d cached entries, want 2 and 0", calls.Load(), len(client.cache.entries))
+	}
+}
+
+func TestCheckNotModified(t *testing.T) {
+	updatedAt := time.Date(2025, 3, 16, 12, 0, 0, 0, time.UTC)
+	var gotIMS string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotIMS = r.Header.Get("If-Modified-Since")
+		w.WriteHeader(http.StatusNotModified)
+	}))
+	defer server.Close()
+
+	client, err := New(WithBackend(server.URL))
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	prURL := "https://github.com/owner/repo/pull/123"
+	ctx := context.Background()
+
+	// Without a cached response the caller is told to reuse its own result
+	if _, err := client.Check(ctx, prURL, "alice", updatedAt); !errors.Is(err, ErrNotModified) {
+		t.Errorf("Check() without cache error = %v, want ErrNotModified", err)
+	}
+	if want := updatedAt.Format(http.TimeFormat); gotIMS != want {
+		t.Errorf("If-Modified-Since without cache = %q, want %q", gotIMS, want)
+	}
+
+	// An expired cache entry is revalidated rather than discarded
+	key := cacheKey(CheckRequest{URL: prURL, User: "alice", UpdatedAt: updatedAt})
+	client.cache.store(key, &CheckResponse{Commit: "cached"}, time.Now(), time.Now().Add(-time.Minute))
+	resp, err := client.Check(ctx, prURL, "alice", updatedAt)
+	if err != nil {
+		t.Fatalf("Check() failed: %v", err)
+	}
+	if resp.Commit != "cached" {
+		t.Errorf("Commit = %q, want the cached response", resp.Commit)
+	}
+	if want := updatedAt.Format(http.TimeFormat); gotIMS != want {
+		t.Errorf("If-Modified-Since = %q, want %q", gotIMS, want)
+	}
+}
+
+func TestCacheWithClock(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Cache-Control", "max-age=60")
+		if err := json.NewEncoder(w).Encode(CheckResponse{}); err != nil {
+			t.Errorf("failed to encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	now := time.Date(2025, 3, 16, 12, 0, 0, 0, time.UTC)
+	client, err := New(WithBackend(server.URL), WithClock(func() time.Time { return now }))
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+
+	prURL := "https://github.com/owner/repo/pull/1"
+	updatedAt := now.Add(-time.Hour)
+	for _, advance := range []time.Duration{0, 59 * time.Second, 2 * time.Second} {
+		now = now.Add(advance)
+		if _, err := client.Check(context.Background(), prURL, "alice", updatedAt); err != nil {
+			t.Fatalf("Check() failed: %v", err)
+		}
+	}
+	// Fetched, served from cache at 59s, expired at 61s
+	if calls.Load() != 2 {
+		t.Errorf("made %d calls, want 2", calls.Load())
+	}
+}
+
+func TestCachedResponseIsolation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=60")
+		resp := CheckResponse{Analysis: Analysis{
+			NextAction:     map[string]Action{"alice": {Kind: ActionReview}},
+			Tags:           []string{"small"},
+			SecondsInState: map[string]int{string(StateInDraft): 10},
+		}}
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			t.Errorf("failed to encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	client, err := New(WithBackend(server.URL))
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	ctx := context.Background()
+	const prURL = "https://github.com/owner/repo/pull/1"
+	updatedAt := time.Now()
+
+	// Mutate both the response that populated the cache and one served from it.
+	for range 2 {
+		r, err := client.Check(ctx, prURL, "alice", updatedAt)
+		if err != nil {
+			t.Fatalf("Check() failed: %v", err)
+		}
+		delete(r.Analysis.NextAction, "alice")
+		r.Analysis.Tags[0] = "MUTATED"
+		r.Analysis.SecondsInState[string(StateInDraft)] = 0
+	}
+
+	got, err := client.Check(ctx, prURL, "alice", updatedAt)
+	if err != nil {
+		t.Fatalf("Check() failed: %v", err)
+	}
+	if _, ok := got.Analysis.NextAction["alice"]; !ok {
+		t.Errorf("NextAction = %v, want alice's action preserved", got.Analysis.NextAction)
+	}
+	if got.Analysis.Tags[0] != "small" {
+		t.Errorf("Tags = %q, want [small]", got.Analysis.Tags)
+	}
+	if got.Analysis.SecondsInState[string(StateInDraft)] != 10 {
+		t.Errorf("SecondsInState = %v, want %s: 10", got.Analysis.SecondsInState, StateInDraft)
+	}
+}
@@ -4,18 +4,25 @@ package turn
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
+	"crypto/rand"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"log"
+	mrand "math/rand/v2"
+	"net"
 	"net/http"
 	"net/url"
+	"regexp"
+	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
-
-	"github.com/codeGROOVE-dev/retry"
 )
 
 const (
@@ -28,18 +35,91 @@ const (
 	retryAttempts   = 4 // 1 initial + 3 retries
 	logMaxLength    = 100
 	errorMaxLength  = 500
+	maxMetadataSize = 4 * 1024
+	decodeBodyLen   = 200 // Body bytes kept in a DecodeError
+
+	defaultTokenSkew  = 60 * time.Second
+	defaultFutureSkew = 5 * time.Minute
+	requestIDHeader   = "X-Request-ID"
+	githubTokenHeader = "X-GitHub-Token"
+	redactedToken     = "***redacted***"
+
+	defaultValidatePath = "/v1/validate"
+	defaultAccept       = "application/json"
+	defaultDialTimeout  = 5 * time.Second
+	defaultGitHubHost   = "github.com"
 )
 
+// prPathPattern matches the path of a GitHub or Gitea pull request, or a
+// GitLab merge request (whose project path may include subgroups).
+var prPathPattern = regexp.MustCompile(`^/.+/(?:pull|pulls|merge_requests)/\d+(?:/.*)?$`)
+
+// languageTagPattern loosely matches a BCP 47 language tag: a 2-8 letter
+// primary subtag followed by optional alphanumeric subtags.
+var languageTagPattern = regexp.MustCompile(`^[A-Za-z]{2,8}(?:-[A-Za-z0-9]{1,8})*$`)
+
+// bearerPattern matches a bearer credential, as in an Authorization header.
+var bearerPattern = regexp.MustCompile(`(?i)(bearer\s+)[^\s"',]+`)
+
+// Checker is the recommended integration point for code that uses the Turn API:
+// accept a Checker rather than a *Client so tests can substitute a fake.
+// *Client implements Checker; see the turntest package for a fake.
+type Checker interface {
+	Check(ctx context.Context, prURL, user string, updatedAt time.Time) (*CheckResponse, error)
+	CurrentUser(ctx context.Context) (string, error)
+}
+
+var _ Checker = (*Client)(nil)
+
 // Client communicates with the Turn API.
 // Client methods are safe for concurrent use after initialization.
-// Set* methods should only be called during setup before concurrent use.
+// Set* methods other than SetAuthToken should only be called during setup
+// before concurrent use.
 type Client struct {
-	httpClient    *http.Client
-	logger        *log.Logger
-	baseURL       string
-	authToken     string
-	noCache       bool
-	includeEvents bool
+	httpClient     *http.Client
+	transport      *http.Transport
+	logger         *log.Logger
+	baseURL        string
+	authToken      atomic.Pointer[string]
+	tokenSource    TokenSource
+	cachedToken    string
+	tokenExpiry    time.Time
+	tokenSkew      time.Duration
+	tokenMu        sync.Mutex
+	noCache        bool
+	includeEvents  bool
+	strict         bool
+	requestIDGen   func() string
+	reqTimeout     time.Duration
+	checkTimeout   time.Duration
+	userTimeout    time.Duration
+	shouldRetry    func(resp *http.Response, err error) bool
+	attempts       uint
+	maxRetryAfter  time.Duration
+	language       string
+	validatePath   string
+	cache          responseCache
+	middleware     []func(*http.Request) error
+	signingKey     []byte
+	githubHost     string
+	jitterRand     *mrand.Rand
+	jitterMu       sync.Mutex
+	now            func() time.Time
+	sentToken      atomic.Pointer[string]
+	noRedact       bool
+	futureSkew     time.Duration
+	accepted       []int
+	retryDeadline  time.Duration
+	sem            chan struct{} // Bounds in-flight requests; nil is unbounded
+	configErrs     []error       // Errors from options, reported by New
+	insecure       bool
+	insecureOnce   sync.Once
+	accept         string
+	authHeaders    http.Header // Extra auth headers from WithAuthHeader and WithBasicAuth
+	tokenHeader    string      // Header carrying the GitHub token; "" means Authorization: Bearer
+	idempotencyKey func(CheckRequest) string
+	jitterStrategy JitterStrategy
+	responseFields []string // Sent as the fields query parameter on checks
 }
 
 // NewClient creates a new Turn API client with the specified backend URL.
@@ -58,12 +138,39 @@ func NewClient(baseURL string) (*Client, error) {
 		return nil, errors.New("base URL must use http or https")
 	}
 
+	// Clone the default transport to keep its dial and TLS settings, but set
+	// the proxy explicitly so HTTP_PROXY, HTTPS_PROXY and NO_PROXY are honored.
+	transport, ok := http.DefaultTransport.(*http.Transport)
+	if ok {
+		transport = transport.Clone()
+	} else {
+		transport = &http.Transport{}
+	}
+	transport.Proxy = http.ProxyFromEnvironment
+	transport.DialContext = dialer(defaultDialTimeout).DialContext
+
 	return &Client{
-		baseURL: strings.TrimRight(baseURL, "/"),
+		baseURL:   strings.TrimRight(baseURL, "/"),
+		transport: transport,
 		httpClient: &http.Client{
-			Timeout: clientTimeout,
+			Timeout:   clientTimeout,
+			Transport: transport,
 		},
-		logger: log.New(io.Discard, "", 0),
+		logger:         log.New(io.Discard, "", 0),
+		tokenSkew:      defaultTokenSkew,
+		checkTimeout:   clientTimeout,
+		userTimeout:    clientTimeout,
+		requestIDGen:   newRequestID,
+		idempotencyKey: defaultIdempotencyKey,
+		shouldRetry:    DefaultRetryPredicate,
+		attempts:       retryAttempts,
+		maxRetryAfter:  defaultMaxRetryAfter,
+		validatePath:   defaultValidatePath,
+		githubHost:     defaultGitHubHost,
+		now:            time.Now,
+		futureSkew:     defaultFutureSkew,
+		accept:         defaultAccept,
+		jitterRand:     mrand.New(newTimeSeededSource()), //nolint:gosec // jitter does not need cryptographic randomness
 	}, nil
 }
 
@@ -94,17 +201,272 @@ func WithLogger(logger *log.Logger) Option {
 // WithAuthToken sets the GitHub authentication token.
 func WithAuthToken(token string) Option {
 	return func(c *Client) {
-		c.authToken = token
+		c.authToken.Store(&token)
 	}
 }
 
-// WithNoCache enables or disables caching.
+// WithNoCache enables or disables caching. When disabled, the backend is
+// asked to bypass its cache and responses are not cached locally.
 func WithNoCache(noCache bool) Option {
 	return func(c *Client) {
 		c.noCache = noCache
 	}
 }
 
+// WithDialTimeout bounds how long establishing a TCP connection to the
+// backend may take, so an unreachable host fails fast instead of consuming
+// the whole request timeout. The default is 5 seconds; zero means no limit
+// beyond the request timeout.
+func WithDialTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		if d >= 0 {
+			c.transport.DialContext = dialer(d).DialContext
+		}
+	}
+}
+
+// dialer returns a dialer with http.DefaultTransport's keep-alive and the
+// given connect timeout.
+func dialer(timeout time.Duration) *net.Dialer {
+	return &net.Dialer{Timeout: timeout, KeepAlive: 30 * time.Second}
+}
+
+// WithRequestMiddleware adds a function that can inspect or modify each
+// request after the client has built it and before it is sent, e.g. to add
+// headers. Middlewares run once per request, not per retry, in the order they
+// were added; an error aborts the request. A nil function is ignored.
+func WithRequestMiddleware(mw func(*http.Request) error) Option {
+	return func(c *Client) {
+		if mw != nil {
+			c.middleware = append(c.middleware, mw)
+		}
+	}
+}
+
+// WithGitHubHost sets the GitHub host used by CheckPR to build PR URLs,
+// for GitHub Enterprise Server. The default is github.com.
+func WithGitHubHost(host string) Option {
+	return func(c *Client) {
+		if host != "" {
+			c.githubHost = host
+		}
+	}
+}
+
+// WithClock sets the function the client uses to read the current time, for
+// cache expiry, token refresh, request signing and Retry-After dates. It
+// exists so tests can freeze time; the default is time.Now.
+func WithClock(now func() time.Time) Option {
+	return func(c *Client) {
+		if now != nil {
+			c.now = now
+		}
+	}
+}
+
+// WithTokenRedaction controls whether auth tokens are masked in log output.
+// It is on by default; turn it off only when debugging authentication
+// locally.
+func WithTokenRedaction(enabled bool) Option {
+	return func(c *Client) {
+		c.noRedact = !enabled
+	}
+}
+
+// WithProxy routes requests through the given proxy, overriding the
+// HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables.
+// A nil URL disables proxying.
+func WithProxy(proxyURL *url.URL) Option {
+	return func(c *Client) {
+		if proxyURL == nil {
+			c.transport.Proxy = nil
+			return
+		}
+		c.transport.Proxy = http.ProxyURL(proxyURL)
+	}
+}
+
+// WithEndpointPath overrides the path of the validate endpoint, which defaults
+// to /v1/validate. Use it when the service is mounted under a prefix behind a
+// gateway, e.g. "/turn/v1/validate". The path must start with a slash. The
+// other endpoints, such as /v1/version and /v1/queue, are found next to it,
+// under the same prefix.
+func WithEndpointPath(path string) Option {
+	return func(c *Client) {
+		c.validatePath = path
+	}
+}
+
+// WithResponseFields asks the backend to return only the given fields of
+// each check response, by dotted path such as "analysis.workflow_state", via
+// a fields query parameter on the validate endpoint:
+//
+//	POST /v1/validate?fields=analysis.workflow_state,analysis.ready_to_merge
+//
+// This needs server-side support; backends that do not implement it ignore
+// the parameter and return the full response. Either way the client decodes
+// whatever comes back, and omitted fields are left at their zero values.
+// Empty names are skipped, and calling it again adds to the list.
+func WithResponseFields(fields ...string) Option {
+	return func(c *Client) {
+		for _, f := range fields {
+			if f = strings.TrimSpace(f); f != "" {
+				c.responseFields = append(c.responseFields, f)
+			}
+		}
+	}
+}
+
+// checkPath returns path with the fields query parameter, if any, appended.
+func (c *Client) checkPath(path string) string {
+	if len(c.responseFields) == 0 {
+		return path
+	}
+	return path + "?" + url.Values{"fields": {strings.Join(c.responseFields, ",")}}.Encode()
+}
+
+// apiPath returns the path of the endpoint name alongside the validate
+// endpoint, so "version" is /turn/v1/version under /turn/v1/validate.
+func (c *Client) apiPath(name string) string {
+	return c.validatePath[:strings.LastIndex(c.validatePath, "/")+1] + name
+}
+
+// WithAcceptHeader overrides the Accept header sent to the Turn API, e.g.
+// "application/vnd.turn.v2+json" to opt into a newer response version. The
+// response must still decode as a CheckResponse. New reports an error for an
+// empty value. The default is "application/json".
+func WithAcceptHeader(accept string) Option {
+	return func(c *Client) {
+		c.accept = accept
+	}
+}
+
+// WithAuthHeader adds a header sent with every Turn API request, for
+// gateways that authenticate by their own header such as an API key. Calls
+// combine, and a later call for the same name replaces the earlier one.
+// These headers are applied after the GitHub token, so using the name
+// Authorization replaces the bearer token; see WithBasicAuth to keep both.
+// They are not sent to GitHub by CurrentUser.
+func WithAuthHeader(name, value string) Option {
+	return func(c *Client) {
+		if c.authHeaders == nil {
+			c.authHeaders = make(http.Header)
+		}
+		c.authHeaders.Set(name, value)
+	}
+}
+
+// WithBasicAuth authenticates to a gateway in front of the Turn API with
+// HTTP basic auth. Authorization then carries the basic credentials, and the
+// GitHub token, if any, moves to the X-GitHub-Token header so the two can be
+// combined. CurrentUser still sends the token to GitHub as a bearer token.
+func WithBasicAuth(user, pass string) Option {
+	return func(c *Client) {
+		creds := base64.StdEncoding.EncodeToString([]byte(user + ":" + pass))
+		WithAuthHeader("Authorization", "Basic "+creds)(c)
+		c.tokenHeader = githubTokenHeader
+	}
+}
+
+// setToken puts the GitHub token on a Turn API request: as a bearer token in
+// Authorization by default, or in X-GitHub-Token when WithBasicAuth is set.
+func (c *Client) setToken(r *http.Request, token string) {
+	if c.tokenHeader != "" {
+		r.Header.Set(c.tokenHeader, token)
+	} else {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+	c.sentToken.Store(&token)
+}
+
+// setAuthHeaders copies the WithAuthHeader headers onto r, over the token
+// set by setToken.
+func (c *Client) setAuthHeaders(r *http.Request) {
+	for name, values := range c.authHeaders {
+		r.Header[name] = slices.Clone(values)
+	}
+}
+
+// WithLanguage sets the Accept-Language header so the backend can localize
+// human-readable strings such as Action.Reason. The tag must look like a
+// BCP 47 language tag (e.g. "en", "pt-BR"); New reports an error otherwise.
+// By default no header is sent and the server's default language is used.
+func WithLanguage(tag string) Option {
+	return func(c *Client) {
+		c.language = tag
+	}
+}
+
+// WithStrictDecoding makes Check reject responses containing fields the client
+// does not know about. This is intended for integration testing against new
+// backend releases; the default is lenient for forward compatibility.
+func WithStrictDecoding() Option {
+	return func(c *Client) {
+		c.strict = true
+	}
+}
+
+// WithRequestTimeout bounds each call to the Turn API or GitHub, including
+// retries, independently of the underlying HTTP client timeout. It also sets
+// the Check and CurrentUser timeouts, so to give those their own budgets,
+// pass WithCheckTimeout or WithCurrentUserTimeout after it.
+// If the caller's context has an earlier deadline, that deadline wins.
+func WithRequestTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		c.reqTimeout = d
+		c.checkTimeout = d
+		c.userTimeout = d
+	}
+}
+
+// WithCheckTimeout bounds each call to Check, including retries, so it has a
+// budget of its own rather than sharing one with slower GitHub lookups. The
+// default is 30 seconds; zero or less removes the bound.
+func WithCheckTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		c.checkTimeout = d
+	}
+}
+
+// WithCurrentUserTimeout bounds each call to CurrentUser, which queries
+// GitHub rather than the Turn API, including retries. The default is 30
+// seconds; zero or less removes the bound.
+func WithCurrentUserTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		c.userTimeout = d
+	}
+}
+
+// WithFutureSkew sets how far ahead of the client clock an updatedAt
+// timestamp may be before Check rejects it. The default is 5 minutes.
+func WithFutureSkew(d time.Duration) Option {
+	return func(c *Client) {
+		if d >= 0 {
+			c.futureSkew = d
+		}
+	}
+}
+
+// WithRequestIDGenerator sets the function used to generate the X-Request-ID
+// sent with each check. The default generates a random UUID.
+func WithRequestIDGenerator(fn func() string) Option {
+	return func(c *Client) {
+		if fn != nil {
+			c.requestIDGen = fn
+		}
+	}
+}
+
+// WithAcceptedStatuses treats the given HTTP statuses as success in addition
+// to 200, for gateways that answer 202 Accepted while analysis is queued.
+// The response body must still be valid CheckResponse JSON; it is decoded
+// exactly as for a 200.
+func WithAcceptedStatuses(codes ...int) Option {
+	return func(c *Client) {
+		c.accepted = append(c.accepted, codes...)
+	}
+}
+
 // New creates a new Turn API client with options.
 // If no backend is specified via WithBackend, uses DefaultBackend.
 func New(opts ...Option) (*Client, error) {
@@ -129,12 +491,72 @@ func New(opts ...Option) (*Client, error) {
 		}
 	}
 
+	if err := errors.Join(c.configErrs...); err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(c.validatePath, "/") {
+		return nil, fmt.Errorf("endpoint path %q must start with /", c.validatePath)
+	}
+	if strings.TrimSpace(c.accept) == "" {
+		return nil, errors.New("accept header cannot be empty")
+	}
+	if c.language != "" && !languageTagPattern.MatchString(c.language) {
+		return nil, fmt.Errorf("invalid language tag %q", c.language)
+	}
+
 	return c, nil
 }
 
 // SetAuthToken sets the GitHub authentication token.
+// Unlike the other Set* methods, it is safe to call while requests are in
+// flight, so a refreshed token can be swapped into a live client.
 func (c *Client) SetAuthToken(token string) {
-	c.authToken = token
+	c.authToken.Store(&token)
+}
+
+// WithToken returns a copy of c that authenticates with token instead.
+// The copy shares c's HTTP client, transport (and so its connection pool),
+// logger and configuration, but starts with an empty response cache and no
+// token source, so responses and tokens do not leak between tenants.
+func (c *Client) WithToken(token string) *Client {
+	cp := &Client{
+		httpClient:     c.httpClient,
+		transport:      c.transport,
+		logger:         c.logger,
+		baseURL:        c.baseURL,
+		tokenSkew:      c.tokenSkew,
+		noCache:        c.noCache,
+		includeEvents:  c.includeEvents,
+		strict:         c.strict,
+		requestIDGen:   c.requestIDGen,
+		reqTimeout:     c.reqTimeout,
+		checkTimeout:   c.checkTimeout,
+		userTimeout:    c.userTimeout,
+		shouldRetry:    c.shouldRetry,
+		attempts:       c.attempts,
+		maxRetryAfter:  c.maxRetryAfter,
+		language:       c.language,
+		validatePath:   c.validatePath,
+		middleware:     slices.Clone(c.middleware),
+		signingKey:     c.signingKey,
+		githubHost:     c.githubHost,
+		jitterRand:     mrand.New(newTimeSeededSource()), //nolint:gosec // jitter does not need cryptographic randomness
+		now:            c.now,
+		noRedact:       c.noRedact,
+		futureSkew:     c.futureSkew,
+		accepted:       slices.Clone(c.accepted),
+		retryDeadline:  c.retryDeadline,
+		sem:            c.sem,
+		insecure:       c.insecure,
+		accept:         c.accept,
+		authHeaders:    c.authHeaders.Clone(),
+		tokenHeader:    c.tokenHeader,
+		idempotencyKey: c.idempotencyKey,
+		jitterStrategy: c.jitterStrategy,
+		responseFields: slices.Clone(c.responseFields),
+	}
+	cp.authToken.Store(&token)
+	return cp
 }
 
 // SetLogger sets a custom logger for the client.
@@ -154,62 +576,269 @@ func (c *Client) IncludeEvents() {
 	c.includeEvents = true
 }
 
-// Check validates a PR state at the given URL for the specified user.
-// The updatedAt timestamp is used for caching.
-func (c *Client) Check(ctx context.Context, prURL, user string, updatedAt time.Time) (*CheckResponse, error) {
+// ValidateInput runs the checks that Check performs on its arguments before
+// sending a request, without making any network calls. All problems found are
+// reported together.
+func (c *Client) ValidateInput(prURL, user string, updatedAt time.Time) error {
+	var errs []error
 	if prURL == "" {
-		return nil, errors.New("PR URL cannot be empty")
+		errs = append(errs, errors.New("PR URL cannot be empty"))
+	} else if err := validatePRURL(prURL); err != nil {
+		errs = append(errs, err)
 	}
 	if user == "" {
-		return nil, errors.New("user cannot be empty")
+		errs = append(errs, errors.New("user cannot be empty"))
 	}
 	if updatedAt.IsZero() {
-		return nil, errors.New("updated_at timestamp cannot be zero")
+		errs = append(errs, errors.New("updated_at timestamp cannot be zero"))
+	} else if limit := c.now().Add(c.futureSkew); updatedAt.After(limit) {
+		errs = append(errs, fmt.Errorf("updated_at %s is %v in the future (tolerance %v); check the caller's clock",
+			updatedAt.UTC().Format(time.RFC3339), updatedAt.Sub(c.now()).Round(time.Second), c.futureSkew))
 	}
+	return errors.Join(errs...)
+}
 
-	// Truncate and sanitize for logging
-	logURL := prURL
-	if len(prURL) > logMaxLength {
-		rs := []rune(prURL)
-		if len(rs) > logMaxLength {
-			logURL = string(rs[:logMaxLength]) + "..."
-		} else {
-			logURL = prURL
-		}
+// validateMetadata checks that metadata keys are non-empty and that the map
+// stays within maxMetadataSize once encoded.
+func validateMetadata(md map[string]string) error {
+	if len(md) == 0 {
+		return nil
+	}
+	if _, ok := md[""]; ok {
+		return errors.New("metadata keys cannot be empty")
+	}
+	data, err := json.Marshal(md)
+	if err != nil {
+		return fmt.Errorf("encode metadata: %w", err)
+	}
+	if len(data) > maxMetadataSize {
+		return fmt.Errorf("metadata is %d bytes encoded, limit is %d", len(data), maxMetadataSize)
+	}
+	return nil
+}
+
+// validatePRURL checks that prURL looks like a pull or merge request URL
+// on any supported forge (GitHub, GitLab, Gitea).
+func validatePRURL(prURL string) error {
+	u, err := url.Parse(prURL)
+	if err != nil {
+		return fmt.Errorf("invalid PR URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return errors.New("PR URL must use http or https")
+	}
+	if u.Host == "" || !prPathPattern.MatchString(u.Path) {
+		return fmt.Errorf("not a pull request URL: %s", truncate(prURL, logMaxLength))
+	}
+	return nil
+}
+
+// CheckPR is Check for a PR identified by its components rather than a URL.
+// The URL is built as https://<host>/<owner>/<repo>/pull/<number>, using the
+// host set by WithGitHubHost.
+func (c *Client) CheckPR(ctx context.Context, owner, repo string, number int, user string, updatedAt time.Time) (*CheckResponse, error) {
+	if owner == "" || repo == "" {
+		return nil, errors.New("owner and repo cannot be empty")
 	}
-	c.logger.Printf("checking PR %s for user %s", logURL, user)
+	if strings.Contains(owner, "/") || strings.Contains(repo, "/") {
+		return nil, fmt.Errorf("invalid owner/repo %q/%q", owner, repo)
+	}
+	if number <= 0 {
+		return nil, fmt.Errorf("PR number must be positive, got %d", number)
+	}
+	prURL := (&url.URL{
+		Scheme: "https",
+		Host:   c.githubHost,
+		Path:   fmt.Sprintf("/%s/%s/pull/%d", owner, repo, number),
+	}).String()
+	return c.Check(ctx, prURL, user, updatedAt)
+}
+
+// Check validates a PR state at the given URL for the specified user.
+// The updatedAt timestamp is used for caching.
+func (c *Client) Check(ctx context.Context, prURL, user string, updatedAt time.Time) (*CheckResponse, error) {
+	return c.CheckWithOptions(ctx, prURL, user, updatedAt, CheckOptions{})
+}
+
+// WorkflowState is Check for callers that only need the PR's workflow state,
+// such as a kanban board. The backend has no state-only endpoint, so this
+// performs a full check (served from the cache when possible) and returns
+// just Analysis.WorkflowState, which is empty if the backend did not report
+// one.
+func (c *Client) WorkflowState(ctx context.Context, prURL, user string, updatedAt time.Time) (WorkflowState, error) {
+	result, err := c.Check(ctx, prURL, user, updatedAt)
+	if err != nil {
+		return "", err
+	}
+	return WorkflowState(result.Analysis.WorkflowState), nil
+}
+
+// CheckOptions holds optional per-request parameters for CheckWithOptions.
+type CheckOptions struct {
+	// Commit is the PR head SHA the caller already knows about. When it
+	// matches the head the server has on record, the server may serve its
+	// cached analysis instead of recomputing it.
+	Commit string
+	// Metadata is extra context, such as team or priority, that the server
+	// may use to refine its analysis. Keys must be non-empty and the encoded
+	// map must not exceed 4KB.
+	Metadata map[string]string
+	// IncludeEvents requests the full event list for this check, as
+	// IncludeEvents does for every check on the client.
+	IncludeEvents bool
+	// unconditional omits If-Modified-Since, for WaitUntilReady, whose
+	// updatedAt is the current time and so would always get a 304.
+	unconditional bool
+}
 
-	req := CheckRequest{
+// CheckRequestFor returns the request body CheckWithOptions sends for these
+// arguments, so callers can record exactly what was asked, e.g. in bug
+// reports. It does not validate its arguments.
+func (c *Client) CheckRequestFor(prURL, user string, updatedAt time.Time, opts CheckOptions) CheckRequest {
+	return CheckRequest{
 		URL:           prURL,
 		UpdatedAt:     updatedAt.UTC(),
 		User:          user,
-		IncludeEvents: c.includeEvents,
+		IncludeEvents: c.includeEvents || opts.IncludeEvents,
+		Commit:        opts.Commit,
+		Metadata:      opts.Metadata,
 	}
+}
 
-	var buf bytes.Buffer
-	if err := json.NewEncoder(&buf).Encode(req); err != nil {
+// CheckWithOptions is Check with optional per-request parameters.
+func (c *Client) CheckWithOptions(ctx context.Context, prURL, user string, updatedAt time.Time, opts CheckOptions) (*CheckResponse, error) {
+	if err := c.ValidateInput(prURL, user, updatedAt); err != nil {
+		return nil, err
+	}
+	if err := validateMetadata(opts.Metadata); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := withTimeout(ctx, c.checkTimeout)
+	defer cancel()
+
+	reqID := c.requestIDGen()
+
+	c.logf(reqID, "checking PR %s for user %s", truncate(prURL, logMaxLength), user)
+
+	req := c.CheckRequestFor(prURL, user, updatedAt, opts)
+	key := cacheKey(req)
+	if t := contextToken(ctx); t != "" {
+		key = tokenCacheKey(key, t)
+	}
+	if !c.noCache {
+		if cached, ok := c.cache.load(key, c.now()); ok {
+			c.logf(reqID, "serving cached response")
+			return cached, nil
+		}
+	}
+
+	body, err := encodeJSON(req)
+	if err != nil {
 		return nil, fmt.Errorf("encode request: %w", err)
 	}
 
-	c.logger.Printf("request JSON: %s", buf.String())
+	c.logf(reqID, "request JSON: %s", body)
 
-	endpoint := c.baseURL + "/v1/validate"
-	r, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, &buf)
+	r, err := c.newRequest(ctx, reqID, http.MethodPost, c.checkPath(c.validatePath), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	// Lets the backend answer 304 if the PR has not changed since updatedAt
+	if !opts.unconditional {
+		r.Header.Set("If-Modified-Since", updatedAt.UTC().Format(http.TimeFormat))
+	}
+	// Set once here; every retry reuses r, so all attempts carry the same key
+	if key := c.idempotencyKey(req); key != "" {
+		r.Header.Set(idempotencyKeyHeader, key)
+	}
+
+	var result CheckResponse
+	header, err := c.send(r, &result)
+	if errors.Is(err, ErrNotModified) {
+		cached, ok := c.cache.lookup(key)
+		if c.noCache || !ok {
+			return nil, err
+		}
+		c.logf(reqID, "not modified, serving cached response")
+		result, err = *cached, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if result.URL == "" {
+		result.URL = prURL
+	}
+
+	if !c.noCache {
+		now := c.now()
+		if ttl := cacheTTL(header, now); ttl > 0 {
+			c.cache.store(key, &result, now, now.Add(ttl))
+			c.logf(reqID, "cached response for %v", ttl)
+		}
+	}
+
+	c.logf(reqID, "check complete: %d actions assigned", len(result.Analysis.NextAction))
+	return &result, nil
+}
+
+// newRequest builds a request to the Turn API at path with the standard
+// headers and auth applied.
+func (c *Client) newRequest(ctx context.Context, reqID, method, path string, body io.Reader) (*http.Request, error) {
+	r, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
 	if err != nil {
 		return nil, fmt.Errorf("create request: %w", err)
 	}
 
-	r.Header.Set("Content-Type", "application/json")
+	if body != nil && body != http.NoBody {
+		r.Header.Set("Content-Type", "application/json")
+	}
 	r.Header.Set("User-Agent", userAgent)
-	r.Header.Set("Accept", "application/json")
-	if c.authToken != "" {
-		r.Header.Set("Authorization", "Bearer "+c.authToken)
+	r.Header.Set("Accept", c.accept)
+	r.Header.Set("Accept-Encoding", "gzip")
+	r.Header.Set(requestIDHeader, reqID)
+	token, err := c.token(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		c.setToken(r, token)
 	}
+	c.setAuthHeaders(r)
 	if c.noCache {
 		r.Header.Set("Cache-Control", "no-cache")
 	}
+	if c.language != "" {
+		r.Header.Set("Accept-Language", c.language)
+	}
+	for _, mw := range c.middleware {
+		if err := mw(r); err != nil {
+			return nil, fmt.Errorf("request middleware: %w", err)
+		}
+	}
+	if err := c.sign(r, c.now()); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
 
-	c.logger.Printf("sending request to %s", endpoint)
+// call sends a request to the Turn API at path and decodes a 200 response
+// body into out, returning the response headers. Non-200 responses are
+// returned as *APIError.
+func (c *Client) call(ctx context.Context, reqID, method, path string, body io.Reader, out any) (http.Header, error) {
+	r, err := c.newRequest(ctx, reqID, method, path, body)
+	if err != nil {
+		return nil, err
+	}
+	return c.send(r, out)
+}
+
+// send is call for a request built with newRequest, for callers that need to
+// add headers. A 304 response returns the headers and ErrNotModified.
+func (c *Client) send(r *http.Request, out any) (http.Header, error) {
+	ctx := r.Context()
+	reqID := requestIDOf(r)
+	c.logf(reqID, "sending request to %s", r.URL)
 
 	resp, err := c.doWithRetry(ctx, r)
 	if err != nil {
@@ -218,42 +847,87 @@ func (c *Client) Check(ctx context.Context, prURL, user string, updatedAt time.T
 
 	defer func() {
 		if err := resp.Body.Close(); err != nil {
-			c.logger.Printf("failed to close response body: %v", err)
+			c.logf(reqID, "failed to close response body: %v", err)
 		}
 	}()
 
-	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	data, err := c.readBody(resp)
 	if err != nil {
 		return nil, fmt.Errorf("read response: %w", err)
 	}
 
-	c.logger.Printf("received response: status=%d", resp.StatusCode)
+	c.logf(reqID, "received response: status=%d", resp.StatusCode)
 
-	if resp.StatusCode != http.StatusOK {
-		// For error responses, limit the body size in the error message
-		msg := string(body)
-		if len(body) > errorMaxLength {
-			// Truncate at rune boundary to avoid splitting UTF-8 characters
-			rs := []rune(msg)
-			if len(rs) > errorMaxLength {
-				msg = string(rs[:errorMaxLength]) + "... (truncated)"
-			}
-		}
-		return nil, fmt.Errorf("api request failed with status %d: %s", resp.StatusCode, msg)
+	if resp.StatusCode == http.StatusNotModified {
+		return resp.Header, ErrNotModified
+	}
+	if resp.StatusCode != http.StatusOK && !slices.Contains(c.accepted, resp.StatusCode) {
+		return nil, newAPIError(resp.StatusCode, data, reqID)
 	}
 
-	var result CheckResponse
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, fmt.Errorf("unmarshal response: %w", err)
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if c.strict {
+		dec.DisallowUnknownFields()
+	}
+	if err := dec.Decode(out); err != nil {
+		return nil, newDecodeError(resp.Header.Get("Content-Type"), data, err)
 	}
+	return resp.Header, nil
+}
 
-	c.logger.Printf("check complete: %d actions assigned", len(result.Analysis.NextAction))
-	return &result, nil
+// Exists reports whether the backend knows about the PR at prURL, without
+// computing a full analysis. It sends a HEAD request to the validate endpoint
+// with the URL as a query parameter:
+//
+//	HEAD /v1/validate?url=<prURL>
+//
+// A 200 or 204 response means the PR exists and a 404 means it does not; any
+// other status is returned as an *APIError.
+func (c *Client) Exists(ctx context.Context, prURL string) (bool, error) {
+	if err := validatePRURL(prURL); err != nil {
+		return false, err
+	}
+
+	ctx, cancel := c.withRequestTimeout(ctx)
+	defer cancel()
+
+	reqID := c.requestIDGen()
+	c.logf(reqID, "checking existence of %s", truncate(prURL, logMaxLength))
+
+	r, err := c.newRequest(ctx, reqID, http.MethodHead, c.validatePath+"?"+url.Values{"url": {prURL}}.Encode(), http.NoBody)
+	if err != nil {
+		return false, err
+	}
+
+	resp, err := c.doWithRetry(ctx, r)
+	if err != nil {
+		return false, fmt.Errorf("send request: %w", err)
+	}
+	if err := resp.Body.Close(); err != nil {
+		c.logf(reqID, "failed to close response body: %v", err)
+	}
+
+	c.logf(reqID, "received response: status=%d", resp.StatusCode)
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	default:
+		return false, newAPIError(resp.StatusCode, nil, reqID)
+	}
 }
 
 // CurrentUser retrieves the current authenticated GitHub user's login.
 func (c *Client) CurrentUser(ctx context.Context) (string, error) {
-	if c.authToken == "" {
+	ctx, cancel := withTimeout(ctx, c.userTimeout)
+	defer cancel()
+
+	token, err := c.token(ctx)
+	if err != nil {
+		return "", err
+	}
+	if token == "" {
 		return "", errors.New("no auth token set")
 	}
 
@@ -264,7 +938,8 @@ func (c *Client) CurrentUser(ctx context.Context) (string, error) {
 
 	req.Header.Set("Accept", "application/vnd.github.v3+json")
 	req.Header.Set("User-Agent", userAgent)
-	req.Header.Set("Authorization", "Bearer "+c.authToken)
+	req.Header.Set("Authorization", "Bearer "+token)
+	c.sentToken.Store(&token)
 
 	resp, err := c.doWithRetry(ctx, req)
 	if err != nil {
@@ -273,16 +948,23 @@ func (c *Client) CurrentUser(ctx context.Context) (string, error) {
 
 	defer func() {
 		if err := resp.Body.Close(); err != nil {
-			c.logger.Printf("failed to close response body: %v", err)
+			c.logf("", "failed to close response body: %v", err)
 		}
 	}()
 
+	if rateLimitExhausted(resp) {
+		return "", &RateLimitError{StatusCode: resp.StatusCode, Reset: rateLimitReset(resp.Header)}
+	}
 	if resp.StatusCode != http.StatusOK {
 		body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
 		if err != nil {
 			return "", fmt.Errorf("failed to read response body: %w", err)
 		}
-		return "", fmt.Errorf("github API request failed with status %d: %s", resp.StatusCode, string(body))
+		err = fmt.Errorf("github API request failed with status %d: %s", resp.StatusCode, string(body))
+		if authErr := authError(resp.StatusCode); authErr != nil {
+			err = fmt.Errorf("%w: %w", authErr, err)
+		}
+		return "", err
 	}
 
 	var user struct {
@@ -299,48 +981,148 @@ func (c *Client) CurrentUser(ctx context.Context) (string, error) {
 	return user.Login, nil
 }
 
-// doWithRetry performs an HTTP request with exponential backoff retry.
-func (c *Client) doWithRetry(ctx context.Context, req *http.Request) (*http.Response, error) {
-	var resp *http.Response
+// requestTimeoutError is the context cause set when a per-request timeout
+// fires. It wraps context.DeadlineExceeded so callers can keep using errors.Is.
+type requestTimeoutError struct {
+	timeout time.Duration
+}
 
-	err := retry.Do(
-		func() error {
-			var err error
-			// Close previous response body if it exists
-			if resp != nil && resp.Body != nil {
-				if err := resp.Body.Close(); err != nil {
-					c.logger.Printf("failed to close previous response body: %v", err)
-				}
-			}
-			resp, err = c.httpClient.Do(req) //nolint:bodyclose // closed by caller
-			if err != nil {
-				return err
-			}
+func (e *requestTimeoutError) Error() string {
+	return fmt.Sprintf("per-request timeout of %v exceeded", e.timeout)
+}
 
-			// Only retry on 5xx errors or 429 (rate limit)
-			if resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests {
-				// Read and close the error response body
-				if _, err := io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseSize)); err != nil {
-					c.logger.Printf("failed to drain response body: %v", err)
-				}
-				if err := resp.Body.Close(); err != nil {
-					c.logger.Printf("failed to close response body: %v", err)
-				}
-				return fmt.Errorf("server returned status %d", resp.StatusCode)
-			}
+func (*requestTimeoutError) Unwrap() error { return context.DeadlineExceeded }
 
-			return nil
-		},
-		retry.Context(ctx),
-		retry.Attempts(retryAttempts),
-		retry.Delay(100*time.Millisecond),
-		retry.MaxDelay(5*time.Second),
-		retry.DelayType(retry.BackOffDelay),
-		retry.MaxJitter(300*time.Millisecond),
-		retry.OnRetry(func(n uint, err error) {
-			c.logger.Printf("retrying request (attempt %d): %v", n+1, err)
-		}),
-	)
-
-	return resp, err
+// withRequestTimeout applies the configured per-request timeout to ctx.
+func (c *Client) withRequestTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	return withTimeout(ctx, c.reqTimeout)
+}
+
+// withTimeout bounds ctx by d; a d of zero or less leaves ctx as is.
+// context.WithTimeout keeps the parent's deadline if it is earlier.
+func withTimeout(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	if d <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeoutCause(ctx, d, &requestTimeoutError{timeout: d})
+}
+
+// describeFailure explains a failed request in terms of which deadline, if
+// any, caused it: the per-request timeout, the caller's context, or the
+// HTTP client's transport timeout.
+func (c *Client) describeFailure(ctx context.Context, elapsed time.Duration, err error) string {
+	var timeoutErr *requestTimeoutError
+	switch ctxErr := ctx.Err(); {
+	case errors.As(context.Cause(ctx), &timeoutErr):
+		return fmt.Sprintf("%v after %v", timeoutErr, elapsed)
+	case errors.Is(context.Cause(ctx), errRetryDeadline):
+		return fmt.Sprintf("retry deadline of %v exceeded after %v", c.retryDeadline, elapsed)
+	case errors.Is(ctxErr, context.DeadlineExceeded):
+		return fmt.Sprintf("caller's context deadline exceeded after %v", elapsed)
+	case errors.Is(ctxErr, context.Canceled):
+		return fmt.Sprintf("caller's context cancelled after %v", elapsed)
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return fmt.Sprintf("HTTP client timeout of %v exceeded after %v", c.httpClient.Timeout, elapsed)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		return fmt.Sprintf("failed after %v with %v remaining before deadline", elapsed, time.Until(deadline).Round(time.Millisecond))
+	}
+	return fmt.Sprintf("failed after %v with no deadline set", elapsed)
+}
+
+// readBody reads up to maxResponseSize bytes of the response body,
+// decompressing it first if the server sent it gzip-encoded.
+// The limit applies to the decompressed size.
+func (c *Client) readBody(resp *http.Response) ([]byte, error) {
+	r, err := c.bodyReader(resp)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := r.Close(); err != nil {
+			c.logf(requestIDOf(resp.Request), "failed to close gzip reader: %v", err)
+		}
+	}()
+	return io.ReadAll(io.LimitReader(r, maxResponseSize))
+}
+
+// bodyReader returns a reader over the decompressed response body. Closing it
+// releases the decompressor but not resp.Body.
+func (*Client) bodyReader(resp *http.Response) (io.ReadCloser, error) {
+	if !strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		return io.NopCloser(resp.Body), nil
+	}
+	gz, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("decompress: %w", err)
+	}
+	return gz, nil
+}
+
+// truncate shortens s to at most n runes for logging, marking the cut with "...".
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	rs := []rune(s)
+	if len(rs) <= n {
+		return s
+	}
+	return string(rs[:n]) + "..."
+}
+
+// logf logs a message, prefixed with the request ID when one is known.
+// Auth tokens are redacted from the message unless disabled with
+// WithTokenRedaction.
+func (c *Client) logf(reqID, format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	if reqID != "" {
+		msg = "[" + reqID + "] " + msg
+	}
+	if !c.noRedact {
+		msg = c.redact(msg)
+	}
+	c.logger.Print(msg)
+}
+
+// redact replaces bearer credentials and any token the client has sent with
+// a placeholder.
+func (c *Client) redact(msg string) string {
+	msg = bearerPattern.ReplaceAllString(msg, "${1}"+redactedToken)
+	for _, t := range []string{c.staticToken(), c.lastSentToken()} {
+		if t != "" {
+			msg = strings.ReplaceAll(msg, t, redactedToken)
+		}
+	}
+	return msg
+}
+
+// lastSentToken returns the token most recently put in an Authorization header.
+func (c *Client) lastSentToken() string {
+	if t := c.sentToken.Load(); t != nil {
+		return *t
+	}
+	return ""
+}
+
+// requestIDOf returns the correlation ID attached to req, if any.
+func requestIDOf(req *http.Request) string {
+	if req == nil {
+		return ""
+	}
+	return req.Header.Get(requestIDHeader)
+}
+
+// newRequestID returns a random version 4 UUID.
+func newRequestID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return ""
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
 }
@@ -1,12 +1,19 @@
 package turn
 
 import (
+	"compress/gzip"
 	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"log"
+	"maps"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -253,6 +260,127 @@ func TestClient_CheckWithAuth(t *testing.T) {
 	}
 }
 
+func TestWithToken(t *testing.T) {
+	var got []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "max-age=60")
+		if err := json.NewEncoder(w).Encode(CheckResponse{}); err != nil {
+			t.Errorf("failed to encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	base, err := New(WithBackend(server.URL), WithAuthToken("tenant-a"))
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	other := base.WithToken("tenant-b")
+	if other.httpClient != base.httpClient {
+		t.Error("WithToken() copy does not share the HTTP client")
+	}
+
+	ctx := context.Background()
+	const prURL = "https://github.com/owner/repo/pull/1"
+	at := time.Now()
+	if _, err := base.Check(ctx, prURL, "alice", at); err != nil {
+		t.Fatalf("base Check() failed: %v", err)
+	}
+	// Same request: must not be served from base's cache.
+	if _, err := other.Check(ctx, prURL, "alice", at); err != nil {
+		t.Fatalf("copy Check() failed: %v", err)
+	}
+
+	want := []string{"Bearer tenant-a", "Bearer tenant-b"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Authorization headers = %q, want %q", got, want)
+	}
+}
+
+func TestCheckWithOptionsCommit(t *testing.T) {
+	var got CheckRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(CheckResponse{Commit: got.Commit}); err != nil {
+			t.Errorf("failed to encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	client, err := New(WithBackend(server.URL))
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	const sha = "0123456789abcdef0123456789abcdef01234567"
+	resp, err := client.CheckWithOptions(context.Background(), "https://github.com/owner/repo/pull/1", "alice", time.Now(),
+		CheckOptions{Commit: sha})
+	if err != nil {
+		t.Fatalf("CheckWithOptions() failed: %v", err)
+	}
+	if got.Commit != sha {
+		t.Errorf("request commit = %q, want %q", got.Commit, sha)
+	}
+	if want := client.CheckRequestFor("https://github.com/owner/repo/pull/1", "alice", got.UpdatedAt, CheckOptions{Commit: sha}); !reflect.DeepEqual(got, want) {
+		t.Errorf("request = %+v, want CheckRequestFor() = %+v", got, want)
+	}
+	if resp.Commit != sha {
+		t.Errorf("response commit = %q, want %q", resp.Commit, sha)
+	}
+	if want := "https://github.com/owner/repo/pull/1"; resp.URL != want {
+		t.Errorf("response URL = %q, want %q filled in from the request", resp.URL, want)
+	}
+}
+
+func TestCheckWithOptionsMetadata(t *testing.T) {
+	var got CheckRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(CheckResponse{}); err != nil {
+			t.Errorf("failed to encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	client, err := New(WithBackend(server.URL))
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	ctx := context.Background()
+	const prURL = "https://github.com/owner/repo/pull/1"
+
+	md := map[string]string{"team": "platform", "priority": "high"}
+	if _, err := client.CheckWithOptions(ctx, prURL, "alice", time.Now(), CheckOptions{Metadata: md}); err != nil {
+		t.Fatalf("CheckWithOptions() failed: %v", err)
+	}
+	if !maps.Equal(got.Metadata, md) {
+		t.Errorf("request metadata = %v, want %v", got.Metadata, md)
+	}
+
+	tests := []struct {
+		name    string
+		md      map[string]string
+		wantErr string
+	}{
+		{name: "empty key", md: map[string]string{"": "x"}, wantErr: "metadata keys cannot be empty"},
+		{name: "too large", md: map[string]string{"notes": strings.Repeat("x", maxMetadataSize)}, wantErr: "limit is 4096"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.CheckWithOptions(ctx, prURL, "alice", time.Now(), CheckOptions{Metadata: tt.md})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("CheckWithOptions() error = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
 func TestClient_CheckTimeout(t *testing.T) {
 	// Create a server that delays response
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -277,6 +405,56 @@ func TestClient_CheckTimeout(t *testing.T) {
 	}
 }
 
+// roundTripFunc adapts a function to http.RoundTripper.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestCurrentUserRateLimited(t *testing.T) {
+	reset := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	for _, status := range []int{http.StatusForbidden, http.StatusTooManyRequests} {
+		t.Run(http.StatusText(status), func(t *testing.T) {
+			client, err := New(WithAuthToken("test-token"))
+			if err != nil {
+				t.Fatalf("New() failed: %v", err)
+			}
+			var calls int
+			client.httpClient = &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				calls++
+				h := make(http.Header)
+				h.Set("X-RateLimit-Remaining", "0")
+				h.Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+				return &http.Response{
+					StatusCode: status,
+					Body:       io.NopCloser(strings.NewReader(`{"message":"API rate limit exceeded"}`)),
+					Header:     h,
+					Request:    req,
+				}, nil
+			})}
+
+			_, err = client.CurrentUser(context.Background())
+			if calls != 1 {
+				t.Errorf("requests = %d, want 1 (no retries)", calls)
+			}
+			if !errors.Is(err, ErrRateLimited) {
+				t.Fatalf("CurrentUser() error = %v, want ErrRateLimited", err)
+			}
+			var rlErr *RateLimitError
+			if !errors.As(err, &rlErr) {
+				t.Fatalf("CurrentUser() error = %T, want *RateLimitError", err)
+			}
+			if !rlErr.Reset.Equal(reset) {
+				t.Errorf("Reset = %v, want %v", rlErr.Reset, reset)
+			}
+			if got, want := errors.Is(err, ErrForbidden), status == http.StatusForbidden; got != want {
+				t.Errorf("errors.Is(err, ErrForbidden) = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
 // mockTransport is a custom RoundTripper for testing
 type mockTransport struct {
 	statusCode int
@@ -579,8 +757,8 @@ func TestNewWithOptions(t *testing.T) {
 		if err != nil {
 			t.Fatalf("New() failed: %v", err)
 		}
-		if client.authToken != token {
-			t.Errorf("authToken = %s, want %s", client.authToken, token)
+		if client.staticToken() != token {
+			t.Errorf("authToken = %s, want %s", client.staticToken(), token)
 		}
 	})
 
@@ -619,8 +797,8 @@ func TestNewWithOptions(t *testing.T) {
 		if client.baseURL != customURL {
 			t.Errorf("baseURL = %s, want %s", client.baseURL, customURL)
 		}
-		if client.authToken != token {
-			t.Errorf("authToken = %s, want %s", client.authToken, token)
+		if client.staticToken() != token {
+			t.Errorf("authToken = %s, want %s", client.staticToken(), token)
 		}
 		if !client.noCache {
 			t.Error("noCache should be true")
@@ -637,3 +815,486 @@ func TestNewDefaultClient(t *testing.T) {
 		t.Errorf("baseURL = %s, want %s", client.baseURL, DefaultBackend)
 	}
 }
+
+func TestStrictDecoding(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if _, err := w.Write([]byte(`{"commit":"abc","surprise_field":true,"analysis":{"ready_to_merge":true}}`)); err != nil {
+			t.Errorf("failed to write response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+
+	t.Run("lenient by default", func(t *testing.T) {
+		client, err := New(WithBackend(server.URL))
+		if err != nil {
+			t.Fatalf("New() failed: %v", err)
+		}
+		result, err := client.Check(ctx, "https://github.com/owner/repo/pull/123", "testuser", time.Now())
+		if err != nil {
+			t.Fatalf("Check() failed: %v", err)
+		}
+		if result.Commit != "abc" || !result.Analysis.ReadyToMerge {
+			t.Errorf("unexpected result: %+v", result)
+		}
+	})
+
+	t.Run("strict rejects unknown fields", func(t *testing.T) {
+		client, err := New(WithBackend(server.URL), WithStrictDecoding())
+		if err != nil {
+			t.Fatalf("New() failed: %v", err)
+		}
+		_, err = client.Check(ctx, "https://github.com/owner/repo/pull/123", "testuser", time.Now())
+		if err == nil || !strings.Contains(err.Error(), "surprise_field") {
+			t.Errorf("expected unknown field error, got %v", err)
+		}
+	})
+}
+
+func TestGzipResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept-Encoding") != "gzip" {
+			t.Errorf("expected Accept-Encoding: gzip, got %s", r.Header.Get("Accept-Encoding"))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(w)
+		if err := json.NewEncoder(gz).Encode(CheckResponse{
+			Commit:   "gzipped",
+			Analysis: Analysis{ReadyToMerge: true},
+		}); err != nil {
+			t.Errorf("failed to encode response: %v", err)
+		}
+		if err := gz.Close(); err != nil {
+			t.Errorf("failed to close gzip writer: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	result, err := client.Check(context.Background(), "https://github.com/owner/repo/pull/123", "testuser", time.Now())
+	if err != nil {
+		t.Fatalf("Check() failed: %v", err)
+	}
+	if result.Commit != "gzipped" || !result.Analysis.ReadyToMerge {
+		t.Errorf("unexpected result: %+v", result)
+	}
+}
+
+func TestWithRequestTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(500 * time.Millisecond):
+		case <-r.Context().Done():
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client, err := New(WithBackend(server.URL), WithRequestTimeout(50*time.Millisecond))
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+
+	start := time.Now()
+	_, err = client.Check(context.Background(), "https://github.com/owner/repo/pull/123", "testuser", time.Now())
+	elapsed := time.Since(start)
+	if err == nil {
+		t.Fatal("expected timeout error, got nil")
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context.DeadlineExceeded, got %v", err)
+	}
+	if elapsed > 400*time.Millisecond {
+		t.Errorf("Check() took %v, per-request timeout did not fire", elapsed)
+	}
+}
+
+func TestEndpointTimeouts(t *testing.T) {
+	const prURL = "https://github.com/owner/repo/pull/1"
+	tests := []struct {
+		name      string
+		opts      []Option
+		wantCheck time.Duration // Zero means no deadline
+		wantUser  time.Duration
+	}{
+		{name: "defaults", wantCheck: clientTimeout, wantUser: clientTimeout},
+		{
+			name:      "separate",
+			opts:      []Option{WithCheckTimeout(5 * time.Second), WithCurrentUserTimeout(20 * time.Second)},
+			wantCheck: 5 * time.Second,
+			wantUser:  20 * time.Second,
+		},
+		{name: "request timeout sets both", opts: []Option{WithRequestTimeout(7 * time.Second)}, wantCheck: 7 * time.Second, wantUser: 7 * time.Second},
+		{
+			name:      "override after request timeout",
+			opts:      []Option{WithRequestTimeout(7 * time.Second), WithCurrentUserTimeout(0)},
+			wantCheck: 7 * time.Second,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := New(append([]Option{WithAuthToken("test-token"), WithNoCache(true)}, tt.opts...)...)
+			if err != nil {
+				t.Fatalf("New() failed: %v", err)
+			}
+			remaining := make(map[string]time.Duration)
+			client.httpClient = &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				if deadline, ok := req.Context().Deadline(); ok {
+					remaining[req.URL.Host] = time.Until(deadline)
+				}
+				body := `{"analysis":{}}`
+				if req.URL.Host == "api.github.com" {
+					body = `{"login":"alice"}`
+				}
+				return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body)), Header: make(http.Header), Request: req}, nil
+			})}
+
+			if _, err := client.Check(context.Background(), prURL, "alice", time.Now()); err != nil {
+				t.Fatalf("Check() failed: %v", err)
+			}
+			if _, err := client.CurrentUser(context.Background()); err != nil {
+				t.Fatalf("CurrentUser() failed: %v", err)
+			}
+			backend, err := url.Parse(client.baseURL)
+			if err != nil {
+				t.Fatalf("parse base URL: %v", err)
+			}
+			for host, want := range map[string]time.Duration{backend.Host: tt.wantCheck, "api.github.com": tt.wantUser} {
+				got := remaining[host]
+				if got > want || got < want-time.Second {
+					t.Errorf("%s: deadline %v away, want about %v", host, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestTimeoutDiagnostics(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(500 * time.Millisecond):
+		case <-r.Context().Done():
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	prURL := "https://github.com/owner/repo/pull/123"
+	tests := []struct {
+		name   string
+		setup  func(c *Client) (context.Context, context.CancelFunc)
+		opts   []Option
+		reason string
+	}{
+		{
+			name:   "per-request timeout",
+			opts:   []Option{WithRequestTimeout(50 * time.Millisecond)},
+			setup:  func(*Client) (context.Context, context.CancelFunc) { return context.WithCancel(context.Background()) },
+			reason: "per-request timeout of 50ms exceeded",
+		},
+		{
+			name: "caller deadline",
+			setup: func(*Client) (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.Background(), 50*time.Millisecond)
+			},
+			reason: "caller's context deadline exceeded",
+		},
+		{
+			name: "transport timeout",
+			opts: []Option{WithoutRetries()},
+			setup: func(c *Client) (context.Context, context.CancelFunc) {
+				c.httpClient.Timeout = 50 * time.Millisecond
+				return context.WithCancel(context.Background())
+			},
+			reason: "HTTP client timeout of 50ms exceeded",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logs strings.Builder
+			client, err := New(append([]Option{WithBackend(server.URL), WithLogger(log.New(&logs, "", 0))}, tt.opts...)...)
+			if err != nil {
+				t.Fatalf("New() failed: %v", err)
+			}
+			ctx, cancel := tt.setup(client)
+			defer cancel()
+
+			if _, err := client.Check(ctx, prURL, "alice", time.Now()); err == nil {
+				t.Fatal("expected error, got nil")
+			}
+			if !strings.Contains(logs.String(), tt.reason) {
+				t.Errorf("logs do not contain %q:\n%s", tt.reason, logs.String())
+			}
+		})
+	}
+}
+
+func TestWithFutureSkew(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	client, err := New(WithClock(func() time.Time { return now }), WithFutureSkew(time.Hour))
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	const prURL = "https://github.com/owner/repo/pull/1"
+	if err := client.ValidateInput(prURL, "alice", now.Add(30*time.Minute)); err != nil {
+		t.Errorf("ValidateInput() within tolerance = %v, want nil", err)
+	}
+	if err := client.ValidateInput(prURL, "alice", now.Add(2*time.Hour)); err == nil {
+		t.Error("ValidateInput() beyond tolerance = nil, want error")
+	}
+}
+
+func TestValidateInput(t *testing.T) {
+	client, err := NewDefaultClient()
+	if err != nil {
+		t.Fatalf("NewDefaultClient() failed: %v", err)
+	}
+	now := time.Now()
+
+	tests := []struct {
+		name     string
+		prURL    string
+		user     string
+		at       time.Time
+		wantErrs []string
+	}{
+		{name: "valid GitHub", prURL: "https://github.com/owner/repo/pull/1", user: "alice", at: now},
+		{name: "valid GitLab subgroup", prURL: "https://gitlab.com/group/sub/repo/-/merge_requests/7", user: "alice", at: now},
+		{name: "valid Gitea", prURL: "https://codeberg.org/owner/repo/pulls/3", user: "alice", at: now},
+		{name: "issue URL", prURL: "https://github.com/owner/repo/issues/1", user: "alice", at: now, wantErrs: []string{"not a pull request URL"}},
+		{name: "bad scheme", prURL: "ftp://github.com/owner/repo/pull/1", user: "alice", at: now, wantErrs: []string{"http or https"}},
+		{name: "within future skew", prURL: "https://github.com/owner/repo/pull/1", user: "alice", at: now.Add(time.Minute)},
+		{
+			name:     "far in the future",
+			prURL:    "https://github.com/owner/repo/pull/1",
+			user:     "alice",
+			at:       now.Add(time.Hour),
+			wantErrs: []string{"in the future"},
+		},
+		{
+			name:     "everything wrong",
+			wantErrs: []string{"PR URL cannot be empty", "user cannot be empty", "updated_at timestamp cannot be zero"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := client.ValidateInput(tt.prURL, tt.user, tt.at)
+			if len(tt.wantErrs) == 0 {
+				if err != nil {
+					t.Errorf("ValidateInput() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("ValidateInput() = nil, want error")
+			}
+			for _, want := range tt.wantErrs {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("ValidateInput() = %v, want error containing %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestExists(t *testing.T) {
+	known := "https://github.com/owner/repo/pull/1"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodHead || r.URL.Path != "/v1/validate" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "Bearer test-token" {
+			t.Errorf("Authorization = %q, want Bearer test-token", r.Header.Get("Authorization"))
+		}
+		switch r.URL.Query().Get("url") {
+		case known:
+			w.WriteHeader(http.StatusNoContent)
+		case "https://github.com/owner/repo/pull/500":
+			w.WriteHeader(http.StatusBadRequest)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := New(WithBackend(server.URL), WithAuthToken("test-token"))
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	ctx := context.Background()
+
+	if ok, err := client.Exists(ctx, known); err != nil || !ok {
+		t.Errorf("Exists(known) = (%v, %v), want (true, nil)", ok, err)
+	}
+	if ok, err := client.Exists(ctx, "https://github.com/owner/repo/pull/2"); err != nil || ok {
+		t.Errorf("Exists(unknown) = (%v, %v), want (false, nil)", ok, err)
+	}
+
+	_, err = client.Exists(ctx, "https://github.com/owner/repo/pull/500")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest {
+		t.Errorf("Exists() error = %v, want *APIError with status 400", err)
+	}
+
+	if _, err := client.Exists(ctx, "not-a-url"); err == nil {
+		t.Error("Exists() with invalid URL should fail")
+	}
+}
+
+func TestCheckPR(t *testing.T) {
+	var gotURL string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req CheckRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		gotURL = req.URL
+		if err := json.NewEncoder(w).Encode(CheckResponse{}); err != nil {
+			t.Errorf("failed to encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	client, err := New(WithBackend(server.URL))
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	if _, err := client.CheckPR(ctx, "owner", "repo", 42, "alice", time.Now()); err != nil {
+		t.Fatalf("CheckPR() failed: %v", err)
+	}
+	if want := "https://github.com/owner/repo/pull/42"; gotURL != want {
+		t.Errorf("URL = %q, want %q", gotURL, want)
+	}
+
+	client, err = New(WithBackend(server.URL), WithGitHubHost("ghe.example.com"))
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	if _, err := client.CheckPR(ctx, "owner", "repo", 7, "alice", time.Now()); err != nil {
+		t.Fatalf("CheckPR() failed: %v", err)
+	}
+	if want := "https://ghe.example.com/owner/repo/pull/7"; gotURL != want {
+		t.Errorf("URL = %q, want %q", gotURL, want)
+	}
+
+	for _, tt := range []struct {
+		owner, repo string
+		number      int
+	}{
+		{"", "repo", 1},
+		{"owner", "", 1},
+		{"owner", "repo", 0},
+		{"owner/x", "repo", 1},
+	} {
+		if _, err := client.CheckPR(ctx, tt.owner, tt.repo, tt.number, "alice", time.Now()); err == nil {
+			t.Errorf("CheckPR(%q, %q, %d) should fail", tt.owner, tt.repo, tt.number)
+		}
+	}
+}
+
+func TestWorkflowState(t *testing.T) {
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			return
+		}
+		resp := CheckResponse{Analysis: Analysis{WorkflowState: string(StateApprovedWaitingForMerge)}}
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			t.Errorf("failed to encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	client, err := New(WithBackend(server.URL), WithNoCache(true), WithoutRetries())
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	ctx := context.Background()
+	got, err := client.WorkflowState(ctx, "https://github.com/owner/repo/pull/1", "alice", time.Now())
+	if err != nil {
+		t.Fatalf("WorkflowState() failed: %v", err)
+	}
+	if got != StateApprovedWaitingForMerge {
+		t.Errorf("WorkflowState() = %q, want %q", got, StateApprovedWaitingForMerge)
+	}
+
+	status = http.StatusInternalServerError
+	if got, err := client.WorkflowState(ctx, "https://github.com/owner/repo/pull/1", "alice", time.Now()); err == nil || got != "" {
+		t.Errorf("WorkflowState() on a server error = %q, %v; want \"\", error", got, err)
+	}
+}
+
+func TestTokenRedaction(t *testing.T) {
+	const token = "ghp_supersecrettoken123"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if err := json.NewEncoder(w).Encode(CheckResponse{}); err != nil {
+			t.Errorf("failed to encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	var buf strings.Builder
+	client, err := New(WithBackend(server.URL), WithAuthToken(token), WithLogger(log.New(&buf, "", 0)))
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	if _, err := client.Check(context.Background(), "https://github.com/owner/repo/pull/1", "alice", time.Now()); err != nil {
+		t.Fatalf("Check() failed: %v", err)
+	}
+	// Simulate a future log line that includes headers or the raw token
+	client.logf("req-1", "headers: Authorization: Bearer %s", token)
+	client.logf("", "token is %s", token)
+
+	logs := buf.String()
+	if strings.Contains(logs, token) {
+		t.Errorf("token leaked into logs:\n%s", logs)
+	}
+	if !strings.Contains(logs, "Authorization: Bearer ***redacted***") {
+		t.Errorf("logs missing redacted Authorization header:\n%s", logs)
+	}
+
+	buf.Reset()
+	client, err = New(WithAuthToken(token), WithLogger(log.New(&buf, "", 0)), WithTokenRedaction(false))
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	client.logf("", "token is %s", token)
+	if !strings.Contains(buf.String(), token) {
+		t.Error("WithTokenRedaction(false) should log the token as is")
+	}
+}
+
+func BenchmarkCheck(b *testing.B) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if _, err := w.Write([]byte(`{"analysis":{"next_action":{}}}`)); err != nil {
+			b.Errorf("failed to write response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	client, err := New(WithBackend(server.URL), WithNoCache(true))
+	if err != nil {
+		b.Fatalf("New() failed: %v", err)
+	}
+	ctx := context.Background()
+	at := time.Now()
+
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := client.Check(ctx, "https://github.com/owner/repo/pull/1", "alice", at); err != nil {
+			b.Fatalf("Check() failed: %v", err)
+		}
+	}
+}
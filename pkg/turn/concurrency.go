@@ -0,0 +1,35 @@
+package turn
+
+import (
+	"context"
+	"fmt"
+)
+
+// WithMaxConcurrency bounds the number of requests the client has in flight
+// at once, across all goroutines and all methods. A request holds its slot
+// from the first attempt until its response body has been read, and callers
+// waiting for a slot give up when their context is done. Copies made with
+// WithToken share the limit. The default, or any n below 1, is unbounded.
+func WithMaxConcurrency(n int) Option {
+	return func(c *Client) {
+		if n < 1 {
+			c.sem = nil
+			return
+		}
+		c.sem = make(chan struct{}, n)
+	}
+}
+
+// acquire waits for a concurrency slot and returns the function that
+// releases it.
+func (c *Client) acquire(ctx context.Context) (func(), error) {
+	if c.sem == nil {
+		return func() {}, nil
+	}
+	select {
+	case c.sem <- struct{}{}:
+		return func() { <-c.sem }, nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("waiting for a request slot: %w", context.Cause(ctx))
+	}
+}
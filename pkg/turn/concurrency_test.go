@@ -0,0 +1,73 @@
+package turn
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithMaxConcurrency(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(CheckResponse{}); err != nil {
+			t.Errorf("failed to encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	client, err := New(WithBackend(server.URL), WithMaxConcurrency(2), WithNoCache(true))
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Go(func() {
+			if _, err := client.Check(context.Background(), "https://github.com/owner/repo/pull/1", "alice", time.Now()); err != nil {
+				t.Errorf("Check() failed: %v", err)
+			}
+		})
+	}
+	wg.Wait()
+
+	if p := peak.Load(); p > 2 {
+		t.Errorf("peak in-flight requests = %d, want at most 2", p)
+	}
+	if n := len(client.sem); n != 0 {
+		t.Errorf("%d slots still held after all checks finished", n)
+	}
+}
+
+func TestWithMaxConcurrencyContext(t *testing.T) {
+	client, err := New(WithMaxConcurrency(1))
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	release, err := client.acquire(context.Background())
+	if err != nil {
+		t.Fatalf("acquire() failed: %v", err)
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := client.acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("acquire() with a full semaphore = %v, want context.DeadlineExceeded", err)
+	}
+}
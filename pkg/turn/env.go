@@ -0,0 +1,52 @@
+package turn
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Environment variables read by NewFromEnv.
+const (
+	EnvBackend    = "TURN_BACKEND"     // Backend URL, as for WithBackend
+	EnvAuthToken  = "TURN_AUTH_TOKEN"  // GitHub token, as for WithAuthToken
+	EnvNoCache    = "TURN_NO_CACHE"    // Boolean ("1", "true", ...), as for WithNoCache
+	EnvTimeout    = "TURN_TIMEOUT"     // Go duration ("15s"), as for WithRequestTimeout
+	EnvGitHubHost = "TURN_GITHUB_HOST" // GitHub host, as for WithGitHubHost
+)
+
+// NewFromEnv creates a client configured from the TURN_* environment
+// variables listed above. Unset or empty variables keep the defaults.
+// Options passed in opts are applied after the environment, so they take
+// precedence over it. Malformed values are reported as errors.
+func NewFromEnv(opts ...Option) (*Client, error) {
+	var envOpts []Option
+	if v := os.Getenv(EnvBackend); v != "" {
+		envOpts = append(envOpts, WithBackend(v))
+	}
+	if v := os.Getenv(EnvAuthToken); v != "" {
+		envOpts = append(envOpts, WithAuthToken(v))
+	}
+	if v := os.Getenv(EnvNoCache); v != "" {
+		noCache, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q: %w", EnvNoCache, v, err)
+		}
+		envOpts = append(envOpts, WithNoCache(noCache))
+	}
+	if v := os.Getenv(EnvTimeout); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q: %w", EnvTimeout, v, err)
+		}
+		if d <= 0 {
+			return nil, fmt.Errorf("invalid %s %q: must be positive", EnvTimeout, v)
+		}
+		envOpts = append(envOpts, WithRequestTimeout(d))
+	}
+	if v := os.Getenv(EnvGitHubHost); v != "" {
+		envOpts = append(envOpts, WithGitHubHost(v))
+	}
+	return New(append(envOpts, opts...)...)
+}
@@ -0,0 +1,66 @@
+package turn
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewFromEnv(t *testing.T) {
+	t.Setenv(EnvBackend, "https://turn.example.com/")
+	t.Setenv(EnvAuthToken, "env-token")
+	t.Setenv(EnvNoCache, "true")
+	t.Setenv(EnvTimeout, "15s")
+	t.Setenv(EnvGitHubHost, "ghe.example.com")
+
+	client, err := NewFromEnv()
+	if err != nil {
+		t.Fatalf("NewFromEnv() failed: %v", err)
+	}
+	if client.baseURL != "https://turn.example.com" {
+		t.Errorf("baseURL = %q, want https://turn.example.com", client.baseURL)
+	}
+	if client.staticToken() != "env-token" || !client.noCache || client.reqTimeout != 15*time.Second ||
+		client.githubHost != "ghe.example.com" {
+		t.Errorf("client = {token %q, noCache %v, timeout %v, host %q}, want env values",
+			client.staticToken(), client.noCache, client.reqTimeout, client.githubHost)
+	}
+
+	// Explicit options win over the environment
+	client, err = NewFromEnv(WithAuthToken("explicit"))
+	if err != nil {
+		t.Fatalf("NewFromEnv() failed: %v", err)
+	}
+	if client.staticToken() != "explicit" {
+		t.Errorf("token = %q, want explicit", client.staticToken())
+	}
+}
+
+func TestNewFromEnvDefaults(t *testing.T) {
+	for _, k := range []string{EnvBackend, EnvAuthToken, EnvNoCache, EnvTimeout, EnvGitHubHost} {
+		t.Setenv(k, "")
+	}
+	client, err := NewFromEnv()
+	if err != nil {
+		t.Fatalf("NewFromEnv() failed: %v", err)
+	}
+	if client.baseURL != DefaultBackend || client.githubHost != defaultGitHubHost || client.noCache {
+		t.Errorf("client = {%q, %q, %v}, want defaults", client.baseURL, client.githubHost, client.noCache)
+	}
+}
+
+func TestNewFromEnvInvalid(t *testing.T) {
+	tests := []struct{ key, value string }{
+		{EnvNoCache, "maybe"},
+		{EnvTimeout, "soon"},
+		{EnvTimeout, "-1s"},
+		{EnvBackend, "ftp://turn.example.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key+"="+tt.value, func(t *testing.T) {
+			t.Setenv(tt.key, tt.value)
+			if _, err := NewFromEnv(); err == nil {
+				t.Errorf("NewFromEnv() with %s=%q should fail", tt.key, tt.value)
+			}
+		})
+	}
+}
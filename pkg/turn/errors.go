@@ -0,0 +1,141 @@
+package turn
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+var (
+	// ErrUnauthorized indicates the API rejected the auth token as missing or invalid (HTTP 401).
+	// Callers should re-authenticate rather than retry.
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrForbidden indicates the auth token is valid but lacks access to the resource (HTTP 403).
+	ErrForbidden = errors.New("forbidden")
+	// ErrNotModified indicates the backend answered 304 Not Modified and no
+	// cached response was available; the caller's previous result still holds.
+	ErrNotModified = errors.New("not modified")
+	// ErrRateLimited indicates GitHub's API rate limit is exhausted. The
+	// returned error is a *RateLimitError carrying the reset time.
+	ErrRateLimited = errors.New("rate limited")
+	// ErrNotSupported indicates the backend does not implement the requested
+	// endpoint (HTTP 404 from an optional API such as /v1/version).
+	ErrNotSupported = errors.New("not supported by this backend")
+)
+
+// APIError is returned when the Turn API responds with a non-200 status.
+// For 401 and 403 responses it wraps ErrUnauthorized or ErrForbidden, so
+// callers can test for them with errors.Is.
+type APIError struct {
+	Body       string // Response body, truncated for readability
+	RequestID  string // Value of the X-Request-ID header sent with the request
+	StatusCode int
+}
+
+func (e *APIError) Error() string {
+	if e.RequestID == "" {
+		return fmt.Sprintf("api request failed with status %d: %s", e.StatusCode, e.Body)
+	}
+	return fmt.Sprintf("api request failed with status %d: %s (request id %s)", e.StatusCode, e.Body, e.RequestID)
+}
+
+// Unwrap returns ErrUnauthorized or ErrForbidden for auth failures, and nil otherwise.
+func (e *APIError) Unwrap() error {
+	return authError(e.StatusCode)
+}
+
+// RetryExhaustedError is returned when every attempt of a request failed
+// with a retryable error. It unwraps to LastErr, so a final 5xx or transport
+// error can still be inspected with errors.Is and errors.As.
+type RetryExhaustedError struct {
+	LastErr       error
+	Attempts      int
+	TotalDuration time.Duration
+}
+
+func (e *RetryExhaustedError) Error() string {
+	return fmt.Sprintf("giving up after %d attempts in %v: %v", e.Attempts, e.TotalDuration.Round(time.Millisecond), e.LastErr)
+}
+
+// Unwrap returns the error from the final attempt.
+func (e *RetryExhaustedError) Unwrap() error {
+	return e.LastErr
+}
+
+// RateLimitError is returned when GitHub reports that the rate limit is
+// exhausted (X-RateLimit-Remaining: 0). Such requests are not retried, since
+// nothing will succeed before Reset. It matches ErrRateLimited with
+// errors.Is, and also ErrForbidden when GitHub answered 403.
+type RateLimitError struct {
+	Reset      time.Time // When the limit resets; zero if GitHub did not say
+	StatusCode int
+}
+
+func (e *RateLimitError) Error() string {
+	if e.Reset.IsZero() {
+		return fmt.Sprintf("github rate limit exceeded (status %d)", e.StatusCode)
+	}
+	return fmt.Sprintf("github rate limit exceeded (status %d); resets at %s", e.StatusCode, e.Reset.Format(time.RFC3339))
+}
+
+// Unwrap returns ErrRateLimited, plus the auth sentinel for the status, if any.
+func (e *RateLimitError) Unwrap() []error {
+	if authErr := authError(e.StatusCode); authErr != nil {
+		return []error{ErrRateLimited, authErr}
+	}
+	return []error{ErrRateLimited}
+}
+
+// DecodeError is returned when a response body is not the JSON the client
+// expected, as when a misconfigured gateway answers 200 with an HTML error
+// page. It keeps the start of the body and the Content-Type so the cause is
+// visible in the message.
+type DecodeError struct {
+	Err         error  // The underlying JSON error
+	ContentType string // Content-Type header of the response
+	Body        string // Start of the response body, truncated for readability
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("unmarshal response: %v (content type %q, body %q)", e.Err, e.ContentType, e.Body)
+}
+
+// Unwrap returns the underlying JSON error.
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// newDecodeError builds a DecodeError, keeping at most decodeBodyLen
+// runes of body.
+func newDecodeError(contentType string, body []byte, err error) *DecodeError {
+	msg := string(body)
+	if rs := []rune(msg); len(rs) > decodeBodyLen {
+		msg = string(rs[:decodeBodyLen]) + "..."
+	}
+	return &DecodeError{Err: err, ContentType: contentType, Body: msg}
+}
+
+// authError maps an HTTP status to its auth sentinel error, if any.
+func authError(status int) error {
+	switch status {
+	case http.StatusUnauthorized:
+		return ErrUnauthorized
+	case http.StatusForbidden:
+		return ErrForbidden
+	default:
+		return nil
+	}
+}
+
+// newAPIError builds an APIError from a non-200 response body, truncating
+// the body at a rune boundary so the message stays readable.
+func newAPIError(status int, body []byte, reqID string) *APIError {
+	msg := string(body)
+	if len(body) > errorMaxLength {
+		if rs := []rune(msg); len(rs) > errorMaxLength {
+			msg = string(rs[:errorMaxLength]) + "... (truncated)"
+		}
+	}
+	return &APIError{StatusCode: status, Body: msg, RequestID: reqID}
+}
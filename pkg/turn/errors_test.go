@@ -0,0 +1,161 @@
+package turn
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAPIErrorRequestID(t *testing.T) {
+	var gotID string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotID = r.Header.Get("X-Request-ID")
+		w.WriteHeader(http.StatusNotFound)
+		if _, err := w.Write([]byte("no such PR")); err != nil {
+			t.Errorf("failed to write response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	var logs strings.Builder
+	client, err := New(
+		WithBackend(server.URL),
+		WithRequestIDGenerator(func() string { return "req-42" }),
+	)
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	client.SetLogger(log.New(&logs, "", 0))
+
+	_, err = client.Check(context.Background(), "https://github.com/owner/repo/pull/123", "testuser", time.Now())
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected *APIError, got %T: %v", err, err)
+	}
+	if apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("StatusCode = %d, want 404", apiErr.StatusCode)
+	}
+	if apiErr.RequestID != "req-42" || gotID != "req-42" {
+		t.Errorf("RequestID = %q, header = %q, want req-42", apiErr.RequestID, gotID)
+	}
+	if !strings.Contains(err.Error(), "req-42") {
+		t.Errorf("error %q does not mention request ID", err)
+	}
+	for line := range strings.Lines(logs.String()) {
+		if !strings.HasPrefix(line, "[req-42] ") {
+			t.Errorf("log line missing request ID: %q", line)
+		}
+	}
+}
+
+func TestNewRequestID(t *testing.T) {
+	a, b := newRequestID(), newRequestID()
+	if len(a) != 36 || a[14] != '4' {
+		t.Errorf("newRequestID() = %q, want a version 4 UUID", a)
+	}
+	if a == b {
+		t.Error("newRequestID() returned the same ID twice")
+	}
+}
+
+func TestAuthErrors(t *testing.T) {
+	tests := []struct {
+		status int
+		want   error
+	}{
+		{http.StatusUnauthorized, ErrUnauthorized},
+		{http.StatusForbidden, ErrForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			var calls int
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				calls++
+				w.WriteHeader(tt.status)
+				if _, err := w.Write([]byte(`{"message":"Bad credentials"}`)); err != nil {
+					t.Errorf("failed to write response: %v", err)
+				}
+			}))
+			defer server.Close()
+
+			client, err := New(WithBackend(server.URL), WithAuthToken("bad-token"))
+			if err != nil {
+				t.Fatalf("New() failed: %v", err)
+			}
+
+			_, err = client.Check(context.Background(), "https://github.com/owner/repo/pull/123", "alice", time.Now())
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Check() error = %v, want %v", err, tt.want)
+			}
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) || !strings.Contains(apiErr.Body, "Bad credentials") {
+				t.Errorf("expected APIError carrying the response body, got %v", err)
+			}
+			if calls != 1 {
+				t.Errorf("made %d calls, want 1 (auth failures are not retried)", calls)
+			}
+		})
+	}
+
+	if errors.Is(&APIError{StatusCode: http.StatusNotFound}, ErrUnauthorized) {
+		t.Error("404 should not match ErrUnauthorized")
+	}
+}
+
+func TestCurrentUserAuthError(t *testing.T) {
+	client, err := New(WithAuthToken("bad-token"))
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	client.httpClient = &http.Client{Transport: &mockTransport{
+		statusCode: http.StatusUnauthorized,
+		response:   `{"message":"Bad credentials"}`,
+	}}
+
+	_, err = client.CurrentUser(context.Background())
+	if !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("CurrentUser() error = %v, want ErrUnauthorized", err)
+	}
+}
+
+func TestDecodeError(t *testing.T) {
+	page := "<html><body>502 Bad Gateway</body></html>" + strings.Repeat(" ", 2*decodeBodyLen)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if _, err := w.Write([]byte(page)); err != nil {
+			t.Errorf("failed to write response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	client, err := New(WithBackend(server.URL))
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	_, err = client.Check(context.Background(), "https://github.com/owner/repo/pull/1", "alice", time.Now())
+
+	var decErr *DecodeError
+	if !errors.As(err, &decErr) {
+		t.Fatalf("Check() error = %v, want *DecodeError", err)
+	}
+	if decErr.ContentType != "text/html; charset=utf-8" {
+		t.Errorf("ContentType = %q, want text/html", decErr.ContentType)
+	}
+	if !strings.HasPrefix(decErr.Body, "<html><body>502 Bad Gateway") || len(decErr.Body) > decodeBodyLen+len("...") {
+		t.Errorf("Body = %q, want the start of the page truncated to %d bytes", decErr.Body, decodeBodyLen)
+	}
+	var syntaxErr *json.SyntaxError
+	if !errors.As(err, &syntaxErr) {
+		t.Errorf("Check() error = %v, want it to wrap the JSON syntax error", err)
+	}
+	if !strings.Contains(err.Error(), `content type "text/html`) {
+		t.Errorf("Error() = %q, want it to mention the content type", err.Error())
+	}
+}
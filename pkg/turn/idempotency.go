@@ -0,0 +1,28 @@
+package turn
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+const idempotencyKeyHeader = "Idempotency-Key"
+
+// WithIdempotencyKeyGenerator sets the function that derives the
+// Idempotency-Key header for a check from its request body. The key is
+// computed once per Check call and sent unchanged on every retry of it, so a
+// server that has already processed the call can recognize the repeat. A
+// generator that returns "" sends no header for that call. The default is a
+// SHA-256 hash of the request fields, so identical checks share a key.
+func WithIdempotencyKeyGenerator(fn func(CheckRequest) string) Option {
+	return func(c *Client) {
+		if fn != nil {
+			c.idempotencyKey = fn
+		}
+	}
+}
+
+// defaultIdempotencyKey hashes the fields that identify a check.
+func defaultIdempotencyKey(req CheckRequest) string {
+	sum := sha256.Sum256([]byte(cacheKey(req)))
+	return hex.EncodeToString(sum[:])
+}
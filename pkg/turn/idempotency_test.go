@@ -0,0 +1,85 @@
+package turn
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestIdempotencyKey(t *testing.T) {
+	var keys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get(idempotencyKeyHeader))
+		if len(keys) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(CheckResponse{}); err != nil {
+			t.Errorf("failed to encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	const prURL = "https://github.com/owner/repo/pull/1"
+	updatedAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		gen  func(CheckRequest) string
+		want string
+	}{
+		{
+			name: "default",
+			want: defaultIdempotencyKey(CheckRequest{URL: prURL, User: "alice", UpdatedAt: updatedAt}),
+		},
+		{
+			name: "custom",
+			gen:  func(req CheckRequest) string { return "key-" + req.User },
+			want: "key-alice",
+		},
+		{
+			name: "disabled",
+			gen:  func(CheckRequest) string { return "" },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys = nil
+			client, err := New(WithBackend(server.URL), WithNoCache(true),
+				WithIdempotencyKeyGenerator(tt.gen), WithRetryRandSource(constSource(math.MaxUint64)))
+			if err != nil {
+				t.Fatalf("New() failed: %v", err)
+			}
+			if _, err := client.Check(context.Background(), prURL, "alice", updatedAt); err != nil {
+				t.Fatalf("Check() failed: %v", err)
+			}
+			if len(keys) != 2 {
+				t.Fatalf("server saw %d attempts, want 2", len(keys))
+			}
+			for i, got := range keys {
+				if got != tt.want {
+					t.Errorf("attempt %d: %s = %q, want %q", i+1, idempotencyKeyHeader, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestDefaultIdempotencyKey(t *testing.T) {
+	req := CheckRequest{URL: "https://github.com/owner/repo/pull/1", User: "alice", UpdatedAt: time.Unix(0, 0)}
+	key := defaultIdempotencyKey(req)
+	if len(key) != 64 {
+		t.Errorf("defaultIdempotencyKey() = %q, want 64 hex digits", key)
+	}
+	if got := defaultIdempotencyKey(req); got != key {
+		t.Errorf("defaultIdempotencyKey() is not stable: %q then %q", key, got)
+	}
+	req.User = "bob"
+	if got := defaultIdempotencyKey(req); got == key {
+		t.Errorf("defaultIdempotencyKey() for a different user = %q, want a different key", got)
+	}
+}
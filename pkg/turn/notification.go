@@ -0,0 +1,38 @@
+package turn
+
+// Notification is a small, flat view of a check result for pushing to chat or
+// email. Its shape is a stable contract for notifiers and does not change
+// when the full CheckResponse does.
+type Notification struct {
+	URL          string     `json:"url"`
+	Title        string     `json:"title"`
+	User         string     `json:"user,omitempty"`
+	Action       ActionKind `json:"action,omitempty"` // The user's action; empty if they have none
+	Reason       string     `json:"reason,omitempty"`
+	Summary      string     `json:"summary"`
+	FailingTests int        `json:"failing_tests"`
+	Critical     bool       `json:"critical,omitempty"`
+	ReadyToMerge bool       `json:"ready_to_merge"`
+}
+
+// Notification builds a Notification for user, who may be "" for a
+// notification not addressed to anyone in particular.
+func (r *CheckResponse) Notification(user string) Notification {
+	if r == nil {
+		return Notification{User: user}
+	}
+	n := Notification{
+		URL:          r.URL,
+		Title:        r.PullRequest.Title,
+		User:         user,
+		Summary:      r.SummaryFor(user),
+		FailingTests: r.Analysis.Checks.Failing,
+		ReadyToMerge: r.Analysis.ReadyToMerge,
+	}
+	if a, ok := r.Analysis.PrimaryActionFor(user); ok && user != "" {
+		n.Action = a.Kind
+		n.Reason = a.Reason
+		n.Critical = a.Critical
+	}
+	return n
+}
@@ -0,0 +1,48 @@
+package turn
+
+import (
+	"testing"
+
+	"github.com/codeGROOVE-dev/prx/pkg/prx"
+)
+
+func TestNotification(t *testing.T) {
+	r := &CheckResponse{
+		URL:         "https://github.com/owner/repo/pull/123",
+		PullRequest: prx.PullRequest{Number: 123, Title: "Fix the widget"},
+		Analysis: Analysis{
+			NextAction: map[string]Action{
+				"alice": {Kind: ActionReview, Reason: "requested reviewer", Critical: true},
+			},
+			Checks: Checks{Failing: 2},
+		},
+	}
+
+	got := r.Notification("alice")
+	want := Notification{
+		URL:          "https://github.com/owner/repo/pull/123",
+		Title:        "Fix the widget",
+		User:         "alice",
+		Action:       ActionReview,
+		Reason:       "requested reviewer",
+		Critical:     true,
+		Summary:      "PR #123: you need to review (2 failing tests)",
+		FailingTests: 2,
+	}
+	if got != want {
+		t.Errorf("Notification(alice) = %+v, want %+v", got, want)
+	}
+
+	got = r.Notification("bob")
+	if got.Action != "" || got.Reason != "" || got.Critical {
+		t.Errorf("Notification(bob) has action fields set: %+v", got)
+	}
+	if got.Summary != "PR #123: waiting on @alice to review (2 failing tests)" {
+		t.Errorf("Notification(bob).Summary = %q", got.Summary)
+	}
+
+	var nilResp *CheckResponse
+	if got := nilResp.Notification("alice"); got != (Notification{User: "alice"}) {
+		t.Errorf("nil Notification(alice) = %+v, want only User set", got)
+	}
+}
@@ -0,0 +1,38 @@
+package turn
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+)
+
+// prComponentsPattern captures owner, repo and number from a GitHub pull
+// request path, allowing trailing segments such as /files or /commits.
+var prComponentsPattern = regexp.MustCompile(`^/([^/]+)/([^/]+)/pull/(\d+)(?:/.*)?$`)
+
+// ParsePRURL extracts the owner, repository and number from a pull request
+// URL such as https://github.com/owner/repo/pull/123. Any host is accepted,
+// including www.github.com and GitHub Enterprise hosts.
+func ParsePRURL(prURL string) (owner, repo string, number int, err error) {
+	if prURL == "" {
+		return "", "", 0, errors.New("PR URL cannot be empty")
+	}
+	u, err := url.Parse(prURL)
+	if err != nil {
+		return "", "", 0, fmt.Errorf("invalid PR URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", "", 0, errors.New("PR URL must use http or https")
+	}
+	m := prComponentsPattern.FindStringSubmatch(u.Path)
+	if u.Host == "" || m == nil {
+		return "", "", 0, fmt.Errorf("not a pull request URL: %s", truncate(prURL, logMaxLength))
+	}
+	number, err = strconv.Atoi(m[3])
+	if err != nil || number <= 0 {
+		return "", "", 0, fmt.Errorf("invalid PR number %q", m[3])
+	}
+	return m[1], m[2], number, nil
+}
@@ -0,0 +1,38 @@
+package turn
+
+import "testing"
+
+func TestParsePRURL(t *testing.T) {
+	tests := []struct {
+		name       string
+		prURL      string
+		wantOwner  string
+		wantRepo   string
+		wantNumber int
+		wantErr    bool
+	}{
+		{name: "github", prURL: "https://github.com/owner/repo/pull/123", wantOwner: "owner", wantRepo: "repo", wantNumber: 123},
+		{name: "www host", prURL: "https://www.github.com/owner/repo/pull/7", wantOwner: "owner", wantRepo: "repo", wantNumber: 7},
+		{name: "trailing path", prURL: "https://github.com/owner/repo/pull/42/files", wantOwner: "owner", wantRepo: "repo", wantNumber: 42},
+		{name: "enterprise host", prURL: "https://ghe.example.com/team/svc/pull/9", wantOwner: "team", wantRepo: "svc", wantNumber: 9},
+		{name: "gitea path", prURL: "https://codeberg.org/owner/repo/pulls/3", wantErr: true},
+		{name: "empty", prURL: "", wantErr: true},
+		{name: "issue", prURL: "https://github.com/owner/repo/issues/1", wantErr: true},
+		{name: "bad scheme", prURL: "ftp://github.com/owner/repo/pull/1", wantErr: true},
+		{name: "zero number", prURL: "https://github.com/owner/repo/pull/0", wantErr: true},
+		{name: "overflowing number", prURL: "https://github.com/owner/repo/pull/99999999999999999999", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			owner, repo, number, err := ParsePRURL(tt.prURL)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePRURL(%q) error = %v, wantErr %v", tt.prURL, err, tt.wantErr)
+			}
+			if owner != tt.wantOwner || repo != tt.wantRepo || number != tt.wantNumber {
+				t.Errorf("ParsePRURL(%q) = %q, %q, %d, want %q, %q, %d",
+					tt.prURL, owner, repo, number, tt.wantOwner, tt.wantRepo, tt.wantNumber)
+			}
+		})
+	}
+}
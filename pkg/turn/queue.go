@@ -0,0 +1,69 @@
+package turn
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// maxQueuePages bounds pagination in CheckAll to guard against a backend
+// that never stops returning cursors.
+const maxQueuePages = 50
+
+// queuePage is a single page of results from the /v1/queue endpoint.
+type queuePage struct {
+	NextCursor string           `json:"next_cursor,omitempty"`
+	Items      []*CheckResponse `json:"items"`
+}
+
+// CheckAll returns check results for every open PR on which user has a pending action.
+//
+// The backend is expected to implement the queue endpoint as:
+//
+//	GET /v1/queue?user=<login>[&cursor=<cursor>][&include_events=true]
+//	200 {"items": [<CheckResponse>, ...], "next_cursor": "<opaque>"}
+//
+// The path follows any prefix set with WithEndpointPath. An empty or missing
+// next_cursor marks the last page. Backends without queue support respond
+// with 404, which is returned as an *APIError.
+func (c *Client) CheckAll(ctx context.Context, user string) ([]*CheckResponse, error) {
+	if user == "" {
+		return nil, errors.New("user cannot be empty")
+	}
+
+	ctx, cancel := c.withRequestTimeout(ctx)
+	defer cancel()
+
+	var all []*CheckResponse
+	cursor := ""
+	for range maxQueuePages {
+		reqID := c.requestIDGen()
+		c.logf(reqID, "fetching queue for user %s (cursor %q)", user, cursor)
+
+		q := url.Values{"user": {user}}
+		if cursor != "" {
+			q.Set("cursor", cursor)
+		}
+		if c.includeEvents {
+			q.Set("include_events", "true")
+		}
+
+		var page queuePage
+		if _, err := c.call(ctx, reqID, http.MethodGet, c.apiPath("queue")+"?"+q.Encode(), http.NoBody, &page); err != nil {
+			return nil, err
+		}
+		all = append(all, page.Items...)
+
+		if page.NextCursor == "" {
+			c.logf(reqID, "queue complete: %d PRs", len(all))
+			return all, nil
+		}
+		if page.NextCursor == cursor {
+			return nil, fmt.Errorf("queue pagination did not advance past cursor %q", cursor)
+		}
+		cursor = page.NextCursor
+	}
+	return nil, fmt.Errorf("queue exceeded %d pages", maxQueuePages)
+}
@@ -0,0 +1,115 @@
+package turn
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCheckAll(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/v1/queue" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer test-token" {
+			t.Errorf("Authorization = %q, want Bearer test-token", got)
+		}
+		if got := r.URL.Query().Get("user"); got != "alice" {
+			t.Errorf("user = %q, want alice", got)
+		}
+
+		var page queuePage
+		switch r.URL.Query().Get("cursor") {
+		case "":
+			page = queuePage{Items: []*CheckResponse{{Commit: "a"}, {Commit: "b"}}, NextCursor: "p2"}
+		case "p2":
+			page = queuePage{Items: []*CheckResponse{{Commit: "c"}}}
+		default:
+			t.Errorf("unexpected cursor %q", r.URL.Query().Get("cursor"))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(page); err != nil {
+			t.Errorf("failed to encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	client, err := New(WithBackend(server.URL), WithAuthToken("test-token"))
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+
+	results, err := client.CheckAll(context.Background(), "alice")
+	if err != nil {
+		t.Fatalf("CheckAll() failed: %v", err)
+	}
+	if len(results) != 3 || results[0].Commit != "a" || results[2].Commit != "c" {
+		t.Errorf("CheckAll() returned %d results, want a, b, c", len(results))
+	}
+
+	if _, err := client.CheckAll(context.Background(), ""); err == nil {
+		t.Error("expected error for empty user")
+	}
+}
+
+func TestCheckAllEndpointPrefix(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/turn/v1/queue" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(queuePage{Items: []*CheckResponse{{Commit: "a"}}}); err != nil {
+			t.Errorf("failed to encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	client, err := New(WithBackend(server.URL), WithEndpointPath("/turn/v1/validate"))
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	results, err := client.CheckAll(context.Background(), "alice")
+	if err != nil {
+		t.Fatalf("CheckAll() failed: %v", err)
+	}
+	if len(results) != 1 {
+		t.Errorf("CheckAll() returned %d results, want 1", len(results))
+	}
+}
+
+func TestCheckAllUnsupported(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	client, err := New(WithBackend(server.URL))
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+
+	_, err = client.CheckAll(context.Background(), "alice")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404 APIError, got %v", err)
+	}
+}
+
+func TestCheckAllStuckCursor(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if err := json.NewEncoder(w).Encode(queuePage{NextCursor: "same"}); err != nil {
+			t.Errorf("failed to encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	client, err := New(WithBackend(server.URL))
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	if _, err := client.CheckAll(context.Background(), "alice"); err == nil {
+		t.Error("expected error when cursor does not advance")
+	}
+}
@@ -0,0 +1,308 @@
+package turn
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// StaleSince returns how long the PR has gone without activity as of now.
+// It returns zero if the last activity time is unknown.
+func (r *CheckResponse) StaleSince(now time.Time) time.Duration {
+	ts := r.Analysis.LastActivity.Timestamp
+	if ts.IsZero() {
+		return 0
+	}
+	return now.Sub(ts)
+}
+
+// IsStale reports whether the PR has had no activity for at least threshold.
+// It returns false if the last activity time is unknown.
+func (r *CheckResponse) IsStale(now time.Time, threshold time.Duration) bool {
+	if r.Analysis.LastActivity.Timestamp.IsZero() {
+		return false
+	}
+	return r.StaleSince(now) >= threshold
+}
+
+// AnalysisAge returns how long ago, as of now, the server computed the
+// analysis. It returns zero if the response has no timestamp.
+func (r *CheckResponse) AnalysisAge(now time.Time) time.Duration {
+	if r.Timestamp.IsZero() {
+		return 0
+	}
+	return now.Sub(r.Timestamp)
+}
+
+// IsFresh reports whether the analysis was computed no more than maxAge
+// before now. A response without a timestamp is never fresh, so callers
+// deciding whether to refetch without the cache will do so.
+func (r *CheckResponse) IsFresh(now time.Time, maxAge time.Duration) bool {
+	if r.Timestamp.IsZero() {
+		return false
+	}
+	return r.AnalysisAge(now) <= maxAge
+}
+
+// Title returns the PR title, or "" if r is nil.
+func (r *CheckResponse) Title() string {
+	if r == nil {
+		return ""
+	}
+	return r.PullRequest.Title
+}
+
+// Author returns the login of the PR author, or "" if r is nil.
+func (r *CheckResponse) Author() string {
+	if r == nil {
+		return ""
+	}
+	return r.PullRequest.Author
+}
+
+// IsDraft reports whether the PR is a draft. It returns false if r is nil.
+func (r *CheckResponse) IsDraft() bool {
+	return r != nil && r.PullRequest.Draft
+}
+
+// AwaitingPublish reports whether the PR is waiting on someone to publish
+// it, i.e. a publish_draft action is assigned. Dashboards can use it to tell
+// drafts apart from PRs that are actually blocked.
+func (r *CheckResponse) AwaitingPublish() bool {
+	if r == nil {
+		return false
+	}
+	for _, a := range r.Analysis.NextAction {
+		if a.Kind == ActionPublishDraft {
+			return true
+		}
+	}
+	return false
+}
+
+// AwaitingAuthor reports whether the ball is in the author's court: the PR
+// author has an action assigned, such as respond, fix_tests or
+// resolve_comments. It returns false if the author is unknown.
+func (r *CheckResponse) AwaitingAuthor() bool {
+	author := r.Author()
+	if author == "" {
+		return false
+	}
+	_, ok := r.Analysis.PrimaryActionFor(author)
+	return ok
+}
+
+// actionPhrases describes each action kind as a verb phrase, e.g.
+// "waiting on @alice to review".
+var actionPhrases = map[ActionKind]string{
+	ActionResolveComments:  "resolve comments",
+	ActionPublishDraft:     "publish the draft",
+	ActionRequestReviewers: "request reviewers",
+	ActionReview:           "review",
+	ActionReReview:         "re-review",
+	ActionReviewDiscussion: "respond to discussion",
+	ActionApprove:          "approve",
+	ActionFixTests:         "fix tests",
+	ActionTestsPending:     "wait for tests",
+	ActionRerunTests:       "rerun tests",
+	ActionRespond:          "respond",
+	ActionFixConflict:      "fix the merge conflict",
+	ActionMerge:            "merge",
+}
+
+// phrase returns the verb phrase for kind, falling back to the kind itself.
+func (k ActionKind) phrase() string {
+	if p, ok := actionPhrases[k]; ok {
+		return p
+	}
+	return strings.ReplaceAll(string(k), "_", " ")
+}
+
+// Summary returns a one-line description of the PR's status, such as
+// "PR #123: waiting on @alice to review (2 failing tests)". Users are listed
+// in sorted order so the output is deterministic. Drafts read
+// "PR #123: draft — publish when ready".
+func (r *CheckResponse) Summary() string {
+	return r.SummaryFor("")
+}
+
+// SummaryFor is like Summary but written from user's perspective: if user
+// has an action it reads "you need to review", and other users are listed
+// after it.
+func (r *CheckResponse) SummaryFor(user string) string {
+	if r == nil {
+		return ""
+	}
+
+	// A draft's publish_draft action is covered by the draft notice.
+	draft := r.IsDraft()
+	var parts []string
+	if draft {
+		parts = append(parts, "draft — publish when ready")
+	}
+	if a, ok := r.Analysis.PrimaryActionFor(user); ok && user != "" && (!draft || a.Kind != ActionPublishDraft) {
+		parts = append(parts, "you need to "+a.Kind.phrase())
+	}
+	var waiting []string
+	for _, u := range r.Analysis.WaitingOn() {
+		if u == user || (draft && r.Analysis.NextAction[u].Kind == ActionPublishDraft) {
+			continue
+		}
+		waiting = append(waiting, fmt.Sprintf("@%s to %s", u, r.Analysis.NextAction[u].Kind.phrase()))
+	}
+	if len(waiting) > 0 {
+		parts = append(parts, "waiting on "+strings.Join(waiting, ", "))
+	}
+	if len(parts) == 0 {
+		parts = append(parts, r.idleStatus())
+	}
+
+	var sb strings.Builder
+	sb.WriteString("PR")
+	if n := r.PullRequest.Number; n > 0 {
+		fmt.Fprintf(&sb, " #%d", n)
+	}
+	sb.WriteString(": ")
+	sb.WriteString(strings.Join(parts, "; "))
+	if n := r.Analysis.Checks.Failing; n > 0 {
+		fmt.Fprintf(&sb, " (%s)", pluralize(n, "failing test"))
+	}
+	return sb.String()
+}
+
+// idleStatus describes a PR that is not waiting on anyone.
+func (r *CheckResponse) idleStatus() string {
+	switch {
+	case r.PullRequest.Merged:
+		return "merged"
+	case r.PullRequest.State == "closed":
+		return "closed"
+	case r.Analysis.ReadyToMerge:
+		return "ready to merge"
+	case r.Analysis.WorkflowState != "":
+		return strings.ToLower(strings.ReplaceAll(r.Analysis.WorkflowState, "_", " "))
+	default:
+		return "no action needed"
+	}
+}
+
+// NeedsConflictResolution reports whether the PR has a merge conflict,
+// either flagged directly or implied by a fix_conflict action.
+func (r *CheckResponse) NeedsConflictResolution() bool {
+	if r == nil {
+		return false
+	}
+	if r.Analysis.MergeConflict {
+		return true
+	}
+	_, ok := r.ConflictResolver()
+	return ok
+}
+
+// ConflictResolver returns the user expected to resolve the merge conflict,
+// i.e. the one assigned the fix_conflict action. If several users are, the
+// first in sorted order is returned. The second return value is false if
+// nobody is.
+func (r *CheckResponse) ConflictResolver() (string, bool) {
+	if r == nil {
+		return "", false
+	}
+	for _, u := range r.Analysis.WaitingOn() {
+		if r.Analysis.NextAction[u].Kind == ActionFixConflict {
+			return u, true
+		}
+	}
+	return "", false
+}
+
+// CanMerge reports whether user can merge the PR now: the backend considers
+// it ready to merge and the user is expected to merge it or has no other
+// action outstanding.
+func (r *CheckResponse) CanMerge(user string) bool {
+	if r == nil || !r.Analysis.ReadyToMerge {
+		return false
+	}
+	a, ok := r.Analysis.PrimaryActionFor(user)
+	return !ok || a.Kind == ActionMerge
+}
+
+// MergeBlockers returns human-readable reasons the PR cannot be merged, such
+// as "2 failing checks" or "waiting on review from @alice". It returns nil if
+// nothing is known to block merging.
+func (r *CheckResponse) MergeBlockers() []string {
+	if r == nil {
+		return nil
+	}
+	a := r.Analysis
+	var blockers []string
+	if r.PullRequest.Draft {
+		blockers = append(blockers, "PR is a draft")
+	}
+	if a.MergeConflict {
+		blockers = append(blockers, "merge conflict")
+	}
+	if n := a.Checks.Failing; n > 0 {
+		blockers = append(blockers, pluralize(n, "failing check"))
+	}
+	if n := a.Checks.Pending + a.Checks.Waiting; n > 0 {
+		blockers = append(blockers, pluralize(n, "pending check"))
+	}
+	if n := a.UnresolvedComments; n > 0 {
+		blockers = append(blockers, pluralize(n, "unresolved comment"))
+	}
+	var reviewers []string
+	for _, u := range a.WaitingOn() {
+		switch a.NextAction[u].Kind {
+		case ActionReview, ActionReReview, ActionApprove:
+			reviewers = append(reviewers, "@"+u)
+		default:
+		}
+	}
+	if len(reviewers) > 0 {
+		blockers = append(blockers, "waiting on review from "+strings.Join(reviewers, ", "))
+	}
+	return blockers
+}
+
+// Flatten returns the response's headline fields as a flat map of scalars,
+// for CSV or warehouse export. The key set is the same for every response:
+//
+//	url, title, author, commit, draft, timestamp, workflow_state, size,
+//	ready_to_merge, approved, merge_conflict, unresolved_comments,
+//	failing_tests, pending_tests, passing_tests, total_tests, blocking_users
+//
+// blocking_users is the sorted users with an assigned action, comma-joined.
+// It returns nil if r is nil.
+func (r *CheckResponse) Flatten() map[string]any {
+	if r == nil {
+		return nil
+	}
+	a := r.Analysis
+	return map[string]any{
+		"url":                 r.URL,
+		"title":               r.PullRequest.Title,
+		"author":              r.PullRequest.Author,
+		"commit":              r.Commit,
+		"draft":               r.PullRequest.Draft,
+		"timestamp":           r.Timestamp,
+		"workflow_state":      a.WorkflowState,
+		"size":                string(a.Size),
+		"ready_to_merge":      a.ReadyToMerge,
+		"approved":            a.Approved,
+		"merge_conflict":      a.MergeConflict,
+		"unresolved_comments": a.UnresolvedComments,
+		"failing_tests":       a.Checks.Failing,
+		"pending_tests":       a.Checks.Pending + a.Checks.Waiting,
+		"passing_tests":       a.Checks.Passing,
+		"total_tests":         a.Checks.Total,
+		"blocking_users":      strings.Join(a.WaitingOn(), ","),
+	}
+}
+
+// pluralize formats a count with noun, adding an "s" unless n is 1.
+func pluralize(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
@@ -0,0 +1,314 @@
+package turn
+
+import (
+	"maps"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/codeGROOVE-dev/prx/pkg/prx"
+)
+
+func TestStaleness(t *testing.T) {
+	now := time.Date(2025, 3, 16, 12, 0, 0, 0, time.UTC)
+	r := &CheckResponse{Analysis: Analysis{LastActivity: LastActivity{Timestamp: now.Add(-72 * time.Hour)}}}
+
+	if got := r.StaleSince(now); got != 72*time.Hour {
+		t.Errorf("StaleSince() = %v, want 72h", got)
+	}
+	if !r.IsStale(now, 48*time.Hour) {
+		t.Error("IsStale(48h) = false, want true")
+	}
+	if r.IsStale(now, 96*time.Hour) {
+		t.Error("IsStale(96h) = true, want false")
+	}
+
+	unknown := &CheckResponse{}
+	if got := unknown.StaleSince(now); got != 0 {
+		t.Errorf("StaleSince() with zero timestamp = %v, want 0", got)
+	}
+	if unknown.IsStale(now, 0) {
+		t.Error("IsStale() with zero timestamp = true, want false")
+	}
+}
+
+func TestAnalysisAge(t *testing.T) {
+	now := time.Date(2025, 3, 16, 12, 0, 0, 0, time.UTC)
+	r := &CheckResponse{Timestamp: now.Add(-10 * time.Minute)}
+
+	if got := r.AnalysisAge(now); got != 10*time.Minute {
+		t.Errorf("AnalysisAge() = %v, want 10m", got)
+	}
+	if !r.IsFresh(now, 15*time.Minute) {
+		t.Error("IsFresh(15m) = false, want true")
+	}
+	if r.IsFresh(now, 5*time.Minute) {
+		t.Error("IsFresh(5m) = true, want false")
+	}
+
+	unknown := &CheckResponse{}
+	if got := unknown.AnalysisAge(now); got != 0 {
+		t.Errorf("AnalysisAge() with zero timestamp = %v, want 0", got)
+	}
+	if unknown.IsFresh(now, time.Hour) {
+		t.Error("IsFresh() with zero timestamp = true, want false")
+	}
+}
+
+func TestPullRequestAccessors(t *testing.T) {
+	r := &CheckResponse{PullRequest: prx.PullRequest{Title: "Fix bug", Author: "alice", Draft: true}}
+	if r.Title() != "Fix bug" || r.Author() != "alice" || !r.IsDraft() {
+		t.Errorf("accessors = (%q, %q, %v), want (\"Fix bug\", \"alice\", true)", r.Title(), r.Author(), r.IsDraft())
+	}
+
+	var nilResp *CheckResponse
+	if nilResp.Title() != "" || nilResp.Author() != "" || nilResp.IsDraft() {
+		t.Error("accessors on nil response should return zero values")
+	}
+}
+
+func TestSummary(t *testing.T) {
+	r := &CheckResponse{
+		PullRequest: prx.PullRequest{Number: 123},
+		Analysis: Analysis{
+			NextAction: map[string]Action{
+				"carol": {Kind: ActionFixTests},
+				"alice": {Kind: ActionReview},
+			},
+			Checks: Checks{Failing: 2},
+		},
+	}
+
+	tests := []struct {
+		name string
+		user string
+		want string
+	}{
+		{name: "overall", want: "PR #123: waiting on @alice to review, @carol to fix tests (2 failing tests)"},
+		{name: "blocking user", user: "alice", want: "PR #123: you need to review; waiting on @carol to fix tests (2 failing tests)"},
+		{name: "bystander", user: "bob", want: "PR #123: waiting on @alice to review, @carol to fix tests (2 failing tests)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := r.SummaryFor(tt.user); got != tt.want {
+				t.Errorf("SummaryFor(%q) = %q, want %q", tt.user, got, tt.want)
+			}
+		})
+	}
+	if r.Summary() != r.SummaryFor("") {
+		t.Error("Summary() should match SummaryFor(\"\")")
+	}
+
+	idle := &CheckResponse{
+		PullRequest: prx.PullRequest{Number: 7},
+		Analysis:    Analysis{WorkflowState: string(StateApprovedWaitingForMerge), Checks: Checks{Failing: 1}},
+	}
+	if got, want := idle.Summary(), "PR #7: approved waiting for merge (1 failing test)"; got != want {
+		t.Errorf("Summary() = %q, want %q", got, want)
+	}
+
+	merged := &CheckResponse{PullRequest: prx.PullRequest{Merged: true}}
+	if got, want := merged.Summary(), "PR: merged"; got != want {
+		t.Errorf("Summary() = %q, want %q", got, want)
+	}
+}
+
+func TestDraftSummary(t *testing.T) {
+	r := &CheckResponse{
+		PullRequest: prx.PullRequest{Number: 9, Draft: true},
+		Analysis: Analysis{NextAction: map[string]Action{
+			"alice": {Kind: ActionPublishDraft},
+			"bob":   {Kind: ActionFixTests},
+		}},
+	}
+	if !r.AwaitingPublish() {
+		t.Error("AwaitingPublish() = false, want true")
+	}
+	if got, want := r.SummaryFor("alice"), "PR #9: draft — publish when ready; waiting on @bob to fix tests"; got != want {
+		t.Errorf("SummaryFor(alice) = %q, want %q", got, want)
+	}
+
+	delete(r.Analysis.NextAction, "bob")
+	if got, want := r.Summary(), "PR #9: draft — publish when ready"; got != want {
+		t.Errorf("Summary() = %q, want %q", got, want)
+	}
+
+	ready := &CheckResponse{Analysis: Analysis{NextAction: map[string]Action{"alice": {Kind: ActionReview}}}}
+	if ready.AwaitingPublish() {
+		t.Error("AwaitingPublish() = true for a PR with no publish_draft action")
+	}
+	var nilResp *CheckResponse
+	if nilResp.AwaitingPublish() {
+		t.Error("nil AwaitingPublish() = true, want false")
+	}
+}
+
+func TestAwaitingAuthor(t *testing.T) {
+	tests := []struct {
+		name string
+		resp *CheckResponse
+		want bool
+	}{
+		{
+			name: "author has an action",
+			resp: &CheckResponse{
+				PullRequest: prx.PullRequest{Author: "alice"},
+				Analysis:    Analysis{NextAction: map[string]Action{"alice": {Kind: ActionFixTests}, "bob": {Kind: ActionReview}}},
+			},
+			want: true,
+		},
+		{
+			name: "only reviewers have actions",
+			resp: &CheckResponse{
+				PullRequest: prx.PullRequest{Author: "alice"},
+				Analysis:    Analysis{NextAction: map[string]Action{"bob": {Kind: ActionReview}}},
+			},
+		},
+		{
+			name: "unknown author",
+			resp: &CheckResponse{Analysis: Analysis{NextAction: map[string]Action{"": {Kind: ActionRespond}}}},
+		},
+		{name: "nil response"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.resp.AwaitingAuthor(); got != tt.want {
+				t.Errorf("AwaitingAuthor() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConflictResolution(t *testing.T) {
+	tests := []struct {
+		name         string
+		analysis     Analysis
+		wantNeeds    bool
+		wantResolver string
+	}{
+		{name: "clean", analysis: Analysis{NextAction: map[string]Action{"bob": {Kind: ActionReview}}}},
+		{name: "flag only", analysis: Analysis{MergeConflict: true}, wantNeeds: true},
+		{
+			name: "action assigned",
+			analysis: Analysis{MergeConflict: true, NextAction: map[string]Action{
+				"bob":   {Kind: ActionReview},
+				"carol": {Kind: ActionFixConflict},
+				"alice": {Kind: ActionFixConflict},
+			}},
+			wantNeeds:    true,
+			wantResolver: "alice",
+		},
+		{
+			name:         "action without flag",
+			analysis:     Analysis{NextAction: map[string]Action{"carol": {Kind: ActionFixConflict}}},
+			wantNeeds:    true,
+			wantResolver: "carol",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &CheckResponse{Analysis: tt.analysis}
+			if got := r.NeedsConflictResolution(); got != tt.wantNeeds {
+				t.Errorf("NeedsConflictResolution() = %v, want %v", got, tt.wantNeeds)
+			}
+			got, ok := r.ConflictResolver()
+			if got != tt.wantResolver || ok != (tt.wantResolver != "") {
+				t.Errorf("ConflictResolver() = (%q, %v), want %q", got, ok, tt.wantResolver)
+			}
+		})
+	}
+}
+
+func TestCanMerge(t *testing.T) {
+	tests := []struct {
+		name     string
+		analysis Analysis
+		want     bool
+	}{
+		{name: "not ready", analysis: Analysis{NextAction: map[string]Action{"alice": {Kind: ActionMerge}}}},
+		{name: "merge action", analysis: Analysis{ReadyToMerge: true, NextAction: map[string]Action{"alice": {Kind: ActionMerge}}}, want: true},
+		{name: "no action", analysis: Analysis{ReadyToMerge: true, NextAction: map[string]Action{"bob": {Kind: ActionMerge}}}, want: true},
+		{name: "other action", analysis: Analysis{ReadyToMerge: true, NextAction: map[string]Action{"alice": {Kind: ActionRespond}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &CheckResponse{Analysis: tt.analysis}
+			if got := r.CanMerge("alice"); got != tt.want {
+				t.Errorf("CanMerge(alice) = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeBlockers(t *testing.T) {
+	r := &CheckResponse{Analysis: Analysis{
+		Checks:             Checks{Failing: 2, Pending: 1},
+		UnresolvedComments: 1,
+		NextAction: map[string]Action{
+			"carol": {Kind: ActionReReview},
+			"alice": {Kind: ActionReview},
+			"bob":   {Kind: ActionFixTests},
+		},
+	}}
+	want := []string{
+		"2 failing checks",
+		"1 pending check",
+		"1 unresolved comment",
+		"waiting on review from @alice, @carol",
+	}
+	if got := r.MergeBlockers(); !slices.Equal(got, want) {
+		t.Errorf("MergeBlockers() = %q, want %q", got, want)
+	}
+	if got := (&CheckResponse{}).MergeBlockers(); got != nil {
+		t.Errorf("MergeBlockers() on a clean PR = %q, want nil", got)
+	}
+}
+
+func TestFlatten(t *testing.T) {
+	ts := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	r := &CheckResponse{
+		Timestamp:   ts,
+		URL:         "https://github.com/owner/repo/pull/1",
+		Commit:      "abc123",
+		PullRequest: prx.PullRequest{Title: "Fix bug", Author: "dana"},
+		Analysis: Analysis{
+			WorkflowState:      string(StateAssignedWaitingForReview),
+			Size:               SizeM,
+			UnresolvedComments: 3,
+			Checks:             Checks{Total: 5, Failing: 1, Pending: 1, Waiting: 1, Passing: 2},
+			NextAction: map[string]Action{
+				"carol": {Kind: ActionReview},
+				"alice": {Kind: ActionReview},
+			},
+		},
+	}
+	want := map[string]any{
+		"url":                 "https://github.com/owner/repo/pull/1",
+		"title":               "Fix bug",
+		"author":              "dana",
+		"commit":              "abc123",
+		"draft":               false,
+		"timestamp":           ts,
+		"workflow_state":      "ASSIGNED_WAITING_FOR_REVIEW",
+		"size":                "M",
+		"ready_to_merge":      false,
+		"approved":            false,
+		"merge_conflict":      false,
+		"unresolved_comments": 3,
+		"failing_tests":       1,
+		"pending_tests":       2,
+		"passing_tests":       2,
+		"total_tests":         5,
+		"blocking_users":      "alice,carol",
+	}
+	if got := r.Flatten(); !maps.Equal(got, want) {
+		t.Errorf("Flatten() = %v, want %v", got, want)
+	}
+
+	if got := (&CheckResponse{}).Flatten(); !slices.Equal(slices.Sorted(maps.Keys(got)), slices.Sorted(maps.Keys(want))) {
+		t.Errorf("Flatten() on an empty response has keys %v, want %v", slices.Sorted(maps.Keys(got)), slices.Sorted(maps.Keys(want)))
+	}
+	if got := (*CheckResponse)(nil).Flatten(); got != nil {
+		t.Errorf("Flatten() on nil = %v, want nil", got)
+	}
+}
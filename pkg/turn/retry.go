@@ -0,0 +1,376 @@
+package turn
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/codeGROOVE-dev/retry"
+)
+
+const (
+	retryBaseDelay       = 100 * time.Millisecond
+	retryMaxDelay        = 5 * time.Second
+	retryMaxJitter       = 300 * time.Millisecond
+	defaultMaxRetryAfter = 30 * time.Second
+)
+
+// DefaultRetryPredicate retries transport errors, 5xx responses, and 429 (rate limit).
+// A DNS lookup that found no such host is not retried, since it almost always
+// means a mistyped backend URL rather than a transient failure.
+func DefaultRetryPredicate(resp *http.Response, err error) bool {
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return false
+		}
+		return true
+	}
+	return resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+}
+
+// rateLimitExhausted reports whether resp is GitHub saying the rate limit is
+// used up, in which case retrying before the reset is pointless.
+func rateLimitExhausted(resp *http.Response) bool {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return false
+	}
+	return resp.Header.Get("X-RateLimit-Remaining") == "0"
+}
+
+// rateLimitReset parses the X-RateLimit-Reset header, a Unix time in seconds.
+func rateLimitReset(h http.Header) time.Time {
+	secs, err := strconv.ParseInt(h.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil || secs <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(secs, 0)
+}
+
+// WithRetryPredicate overrides which results are retried. The predicate is
+// called after every attempt with either a response or a transport error.
+// It must not consume the response body if it returns false, since the body
+// is then handed to the caller. A nil predicate restores DefaultRetryPredicate.
+func WithRetryPredicate(fn func(resp *http.Response, err error) bool) Option {
+	return func(c *Client) {
+		if fn == nil {
+			fn = DefaultRetryPredicate
+		}
+		c.shouldRetry = fn
+	}
+}
+
+// WithRetryAttempts sets the total number of attempts per request, including
+// the first. Values below 1 are treated as 1. The default is 4.
+func WithRetryAttempts(n uint) Option {
+	return func(c *Client) {
+		c.attempts = max(n, 1)
+	}
+}
+
+// WithoutRetries makes each request a single attempt, so failures surface
+// immediately. It is equivalent to WithRetryAttempts(1).
+func WithoutRetries() Option {
+	return WithRetryAttempts(1)
+}
+
+// WithMaxRetryAfter caps how long the client waits when a 429 or 503
+// response carries a Retry-After header. Longer server-requested waits are
+// shortened to d. The default is 30 seconds.
+func WithMaxRetryAfter(d time.Duration) Option {
+	return func(c *Client) {
+		if d >= 0 {
+			c.maxRetryAfter = d
+		}
+	}
+}
+
+// WithRetryRandSource sets the random source used for retry jitter, so tests
+// can make backoff schedules deterministic. The default is seeded from the
+// current time. A nil source restores the default.
+func WithRetryRandSource(src rand.Source) Option {
+	return func(c *Client) {
+		if src == nil {
+			src = newTimeSeededSource()
+		}
+		c.jitterMu.Lock()
+		c.jitterRand = rand.New(src) //nolint:gosec // jitter does not need cryptographic randomness
+		c.jitterMu.Unlock()
+	}
+}
+
+// newTimeSeededSource returns the default jitter source.
+func newTimeSeededSource() rand.Source {
+	seed := uint64(time.Now().UnixNano()) //nolint:gosec // a negative clock is not a concern for a seed
+	return rand.NewPCG(seed, seed>>32)
+}
+
+// JitterStrategy selects how randomness is mixed into retry backoff. Every
+// strategy is capped at 5 seconds between attempts, and a longer Retry-After
+// from the server still takes precedence.
+type JitterStrategy int
+
+const (
+	// AdditiveJitter adds up to 300ms of random delay to plain exponential
+	// backoff (100ms, 200ms, 400ms, ...). It is the default. Waits stay close
+	// to the schedule, so clients that failed together retry nearly together.
+	AdditiveJitter JitterStrategy = iota
+	// FullJitter waits a random time between zero and the exponential
+	// backoff. It spreads simultaneous clients the most and does the least
+	// total waiting, at the cost of sometimes retrying almost immediately.
+	FullJitter
+	// EqualJitter waits half the exponential backoff plus a random time up to
+	// the other half. It guarantees some backoff while still spreading
+	// clients, a middle ground between AdditiveJitter and FullJitter.
+	EqualJitter
+	// DecorrelatedJitter waits a random time between the base delay and three
+	// times the previous wait. Waits grow like exponential backoff on average
+	// but do not stay in step across clients, which best avoids a thundering
+	// herd when many clients poll the same backend; individual waits vary
+	// the most.
+	DecorrelatedJitter
+)
+
+// WithJitterStrategy sets how retry waits are randomized. The default is
+// AdditiveJitter.
+func WithJitterStrategy(s JitterStrategy) Option {
+	return func(c *Client) {
+		if s < AdditiveJitter || s > DecorrelatedJitter {
+			c.configErrs = append(c.configErrs, fmt.Errorf("unknown jitter strategy %d", s))
+			return
+		}
+		c.jitterStrategy = s
+	}
+}
+
+// jitter returns a random duration in [0, retryMaxJitter).
+func (c *Client) jitter() time.Duration {
+	return c.randDuration(retryMaxJitter)
+}
+
+// randDuration returns a random duration in [0, n), or 0 if n is not positive.
+func (c *Client) randDuration(n time.Duration) time.Duration {
+	if n <= 0 {
+		return 0
+	}
+	c.jitterMu.Lock()
+	defer c.jitterMu.Unlock()
+	return time.Duration(c.jitterRand.Int64N(int64(n)))
+}
+
+// backoff returns the jittered wait before attempt n+1 under the client's
+// strategy. prev is the previous wait, or 0 before the first retry.
+func (c *Client) backoff(n uint, err error, cfg *retry.Config, prev time.Duration) time.Duration {
+	switch c.jitterStrategy {
+	case FullJitter:
+		return c.randDuration(retry.BackOffDelay(n, err, cfg))
+	case EqualJitter:
+		half := retry.BackOffDelay(n, err, cfg) / 2
+		return half + c.randDuration(half)
+	case DecorrelatedJitter:
+		prev = max(prev, retryBaseDelay)
+		return retryBaseDelay + c.randDuration(3*prev-retryBaseDelay)
+	default:
+		return retry.BackOffDelay(n, err, cfg) + c.jitter()
+	}
+}
+
+// statusError is returned for a retryable status code. wait holds the delay
+// requested by the server via Retry-After, if any.
+type statusError struct {
+	status int
+	wait   time.Duration
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("server returned status %d", e.status)
+}
+
+// parseRetryAfter parses a Retry-After header value, which is either a number
+// of seconds or an HTTP-date.
+func parseRetryAfter(v string, now time.Time) (time.Duration, bool) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	t, err := http.ParseTime(v)
+	if err != nil {
+		return 0, false
+	}
+	return max(t.Sub(now), 0), true
+}
+
+// retryDelay is jittered backoff, extended to honor any Retry-After wait up
+// to maxRetryAfter. prev is the previous wait, or 0 before the first retry.
+func (c *Client) retryDelay(n uint, err error, cfg *retry.Config, prev time.Duration) time.Duration {
+	d := min(c.backoff(n, err, cfg, prev), retryMaxDelay)
+	var se *statusError
+	if errors.As(err, &se) && se.wait > d {
+		d = min(se.wait, max(c.maxRetryAfter, d))
+	}
+	return d
+}
+
+// errRetryDeadline is the context cause set when WithRetryDeadline fires.
+var errRetryDeadline = fmt.Errorf("retry deadline exceeded: %w", context.DeadlineExceeded)
+
+// WithRetryDeadline caps the total wall-clock time spent on a single request
+// across all of its attempts and the waits between them. When the deadline
+// passes, no further attempts are made and the last error is returned. A
+// zero duration, the default, leaves retries bounded only by the attempt
+// count and the caller's context.
+func WithRetryDeadline(d time.Duration) Option {
+	return func(c *Client) {
+		c.retryDeadline = d
+	}
+}
+
+// closeHook runs onClose once after the response body is closed, to release
+// resources that must outlive doWithRetry, such as the retry deadline's
+// context or a concurrency slot.
+type closeHook struct {
+	io.ReadCloser
+	once    sync.Once
+	onClose func()
+}
+
+func (b *closeHook) Close() error {
+	defer b.once.Do(b.onClose)
+	return b.ReadCloser.Close()
+}
+
+// doWithRetry performs an HTTP request with exponential backoff retry,
+// holding a concurrency slot until the response body is closed and bounded
+// by the retry deadline if one is set.
+func (c *Client) doWithRetry(ctx context.Context, req *http.Request) (*http.Response, error) {
+	c.warnInsecure()
+	release, err := c.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rctx, cancel := ctx, context.CancelFunc(func() {})
+	if c.retryDeadline > 0 {
+		rctx, cancel = context.WithTimeoutCause(ctx, c.retryDeadline, errRetryDeadline)
+		req = req.WithContext(rctx)
+	}
+
+	resp, err := c.retryLoop(rctx, req)
+	if err == nil && resp.StatusCode == http.StatusUnauthorized && c.tokenSource != nil && contextToken(ctx) == "" {
+		resp, err = c.retryWithFreshToken(rctx, req, resp)
+	}
+	if err != nil {
+		cancel()
+		release()
+		if errors.Is(context.Cause(rctx), errRetryDeadline) && ctx.Err() == nil {
+			return nil, fmt.Errorf("retry deadline of %v exceeded: %w", c.retryDeadline, err)
+		}
+		return resp, err
+	}
+	// The caller still has to read the body, so hold on until it is closed.
+	resp.Body = &closeHook{ReadCloser: resp.Body, onClose: func() {
+		cancel()
+		release()
+	}}
+	return resp, nil
+}
+
+// retryLoop performs req, retrying failures with exponential backoff.
+func (c *Client) retryLoop(ctx context.Context, req *http.Request) (*http.Response, error) {
+	reqID := requestIDOf(req)
+	var resp *http.Response
+	var lastErr error // last retryable error, cleared by non-retryable outcomes
+	attempt := 0
+	var prevDelay time.Duration
+
+	start := time.Now()
+	if deadline, ok := ctx.Deadline(); ok {
+		c.logf(reqID, "time budget: %v until deadline", time.Until(deadline).Round(time.Millisecond))
+	}
+
+	err := retry.Do(
+		func() error {
+			var err error
+			// Close previous response body if it exists
+			if resp != nil && resp.Body != nil {
+				if err := resp.Body.Close(); err != nil {
+					c.logf(reqID, "failed to close previous response body: %v", err)
+				}
+			}
+			// Rewind the request body, which the previous attempt consumed
+			attempt++
+			if attempt > 1 && req.GetBody != nil {
+				if req.Body, err = req.GetBody(); err != nil {
+					return retry.Unrecoverable(fmt.Errorf("rewind request body: %w", err))
+				}
+			}
+
+			resp, err = c.do(req) //nolint:bodyclose // closed by caller
+			lastErr = nil
+			if err == nil && rateLimitExhausted(resp) {
+				c.logf(reqID, "rate limit exhausted, not retrying")
+				return nil
+			}
+			if !c.shouldRetry(resp, err) {
+				if err != nil {
+					return retry.Unrecoverable(err)
+				}
+				return nil
+			}
+			if err != nil {
+				lastErr = err
+				return err
+			}
+
+			// Read and close the error response body
+			if _, err := io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseSize)); err != nil {
+				c.logf(reqID, "failed to drain response body: %v", err)
+			}
+			if err := resp.Body.Close(); err != nil {
+				c.logf(reqID, "failed to close response body: %v", err)
+			}
+			se := &statusError{status: resp.StatusCode}
+			if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+				se.wait, _ = parseRetryAfter(resp.Header.Get("Retry-After"), c.now())
+			}
+			lastErr = se
+			return se
+		},
+		retry.Context(ctx),
+		retry.Attempts(c.attempts),
+		retry.Delay(retryBaseDelay),
+		retry.MaxDelay(max(retryMaxDelay, c.maxRetryAfter)),
+		retry.DelayType(func(n uint, err error, cfg *retry.Config) time.Duration {
+			// n is the zero-based index of the upcoming attempt
+			d := c.retryDelay(n, err, cfg, prevDelay)
+			prevDelay = d
+			c.logf(reqID, "retrying %s %s in %v (attempt %d of %d): %v",
+				req.Method, req.URL.Path, d.Round(time.Millisecond), n+1, c.attempts, err)
+			return d
+		}),
+	)
+	if err != nil {
+		elapsed := time.Since(start)
+		c.logf(reqID, "request failed after %d attempts: %s", attempt, c.describeFailure(ctx, elapsed.Round(time.Millisecond), err))
+		if lastErr != nil && uint(attempt) >= c.attempts && context.Cause(ctx) == nil {
+			return resp, &RetryExhaustedError{Attempts: attempt, TotalDuration: elapsed, LastErr: lastErr}
+		}
+	} else if attempt > 1 {
+		c.logf(reqID, "request succeeded after %d attempts in %v", attempt, time.Since(start).Round(time.Millisecond))
+	}
+
+	return resp, err
+}
@@ -0,0 +1,465 @@
+package turn
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"math"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/codeGROOVE-dev/retry"
+)
+
+func TestDefaultRetryPredicate(t *testing.T) {
+	tests := []struct {
+		status int
+		want   bool
+	}{
+		{http.StatusOK, false},
+		{http.StatusNotFound, false},
+		{http.StatusTooManyRequests, true},
+		{http.StatusBadGateway, true},
+	}
+	for _, tt := range tests {
+		if got := DefaultRetryPredicate(&http.Response{StatusCode: tt.status}, nil); got != tt.want {
+			t.Errorf("DefaultRetryPredicate(%d) = %v, want %v", tt.status, got, tt.want)
+		}
+	}
+	if !DefaultRetryPredicate(nil, context.DeadlineExceeded) {
+		t.Error("DefaultRetryPredicate should retry transport errors")
+	}
+}
+
+func TestWithRetryPredicate(t *testing.T) {
+	var calls atomic.Int32
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req CheckRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("attempt %d: failed to decode request: %v", calls.Load()+1, err)
+		}
+		bodies = append(bodies, req.URL)
+		if calls.Add(1) == 1 {
+			// First attempt: a 200 flagged as a wrapped upstream failure
+			w.Header().Set("X-Upstream-Error", "502")
+		}
+		if err := json.NewEncoder(w).Encode(CheckResponse{Commit: "ok"}); err != nil {
+			t.Errorf("failed to encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	client, err := New(
+		WithBackend(server.URL),
+		WithRetryPredicate(func(resp *http.Response, err error) bool {
+			return err != nil || resp.Header.Get("X-Upstream-Error") != ""
+		}),
+	)
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+
+	prURL := "https://github.com/owner/repo/pull/123"
+	result, err := client.Check(context.Background(), prURL, "alice", time.Now())
+	if err != nil {
+		t.Fatalf("Check() failed: %v", err)
+	}
+	if calls.Load() != 2 || result.Commit != "ok" {
+		t.Errorf("made %d calls, want 2", calls.Load())
+	}
+	// The request body must be resent intact on retry
+	for i, b := range bodies {
+		if b != prURL {
+			t.Errorf("attempt %d sent URL %q, want %q", i+1, b, prURL)
+		}
+	}
+}
+
+func TestRetryPredicateNoRetryOn429(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client, err := New(
+		WithBackend(server.URL),
+		WithRetryPredicate(func(resp *http.Response, err error) bool {
+			if err == nil && resp.StatusCode == http.StatusTooManyRequests {
+				return false
+			}
+			return DefaultRetryPredicate(resp, err)
+		}),
+	)
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+
+	_, err = client.Check(context.Background(), "https://github.com/owner/repo/pull/123", "alice", time.Now())
+	if err == nil {
+		t.Fatal("expected error for 429")
+	}
+	if calls.Load() != 1 {
+		t.Errorf("made %d calls, want 1", calls.Load())
+	}
+}
+
+func TestWithoutRetries(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client, err := New(WithBackend(server.URL), WithoutRetries())
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+
+	start := time.Now()
+	_, err = client.Check(context.Background(), "https://github.com/owner/repo/pull/123", "alice", time.Now())
+	if err == nil {
+		t.Fatal("expected error for 503")
+	}
+	if calls.Load() != 1 {
+		t.Errorf("made %d calls, want exactly 1", calls.Load())
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("Check() took %v, expected no retry delay", elapsed)
+	}
+
+	client, err = New(WithBackend(server.URL), WithRetryAttempts(2))
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	calls.Store(0)
+	if _, err := client.Check(context.Background(), "https://github.com/owner/repo/pull/123", "alice", time.Now()); err == nil {
+		t.Fatal("expected error for 503")
+	}
+	if calls.Load() != 2 {
+		t.Errorf("made %d calls, want 2", calls.Load())
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 3, 16, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{value: "", wantOK: false},
+		{value: "3", want: 3 * time.Second, wantOK: true},
+		{value: "-1", wantOK: false},
+		{value: now.Add(90 * time.Second).Format(http.TimeFormat), want: 90 * time.Second, wantOK: true},
+		{value: now.Add(-time.Minute).Format(http.TimeFormat), want: 0, wantOK: true},
+		{value: "soon", wantOK: false},
+	}
+	for _, tt := range tests {
+		got, ok := parseRetryAfter(tt.value, now)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("parseRetryAfter(%q) = (%v, %v), want (%v, %v)", tt.value, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestRetryAfterHonored(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		if err := json.NewEncoder(w).Encode(CheckResponse{}); err != nil {
+			t.Errorf("failed to encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	client, err := New(WithBackend(server.URL))
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+
+	start := time.Now()
+	if _, err := client.Check(context.Background(), "https://github.com/owner/repo/pull/1", "alice", time.Now()); err != nil {
+		t.Fatalf("Check() failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("retried after %v, want at least the 1s Retry-After", elapsed)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("made %d calls, want 2", calls.Load())
+	}
+
+	// The ceiling shortens long server-requested waits
+	calls.Store(0)
+	client, err = New(WithBackend(server.URL), WithMaxRetryAfter(10*time.Millisecond))
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	start = time.Now()
+	if _, err := client.Check(context.Background(), "https://github.com/owner/repo/pull/2", "alice", time.Now()); err != nil {
+		t.Fatalf("Check() failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed >= time.Second {
+		t.Errorf("retried after %v, want the Retry-After capped", elapsed)
+	}
+}
+
+func TestRetryLogging(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		if err := json.NewEncoder(w).Encode(CheckResponse{}); err != nil {
+			t.Errorf("failed to encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+	client, err := New(WithBackend(server.URL), WithLogger(log.New(&buf, "", 0)),
+		WithRetryRandSource(constSource(math.MaxUint64)))
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	if _, err := client.Check(context.Background(), "https://github.com/owner/repo/pull/1", "alice", time.Now()); err != nil {
+		t.Fatalf("Check() failed: %v", err)
+	}
+
+	logs := buf.String()
+	for _, want := range []string{
+		"retrying POST /v1/validate in 400ms (attempt 2 of 4): server returned status 502",
+		"retrying POST /v1/validate in 500ms (attempt 3 of 4)",
+		"request succeeded after 3 attempts",
+	} {
+		if !strings.Contains(logs, want) {
+			t.Errorf("logs missing %q:\n%s", want, logs)
+		}
+	}
+}
+
+func TestRetryExhaustedError(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	client, err := New(WithBackend(server.URL), WithRetryAttempts(2))
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	_, err = client.Check(context.Background(), "https://github.com/owner/repo/pull/1", "alice", time.Now())
+
+	var re *RetryExhaustedError
+	if !errors.As(err, &re) {
+		t.Fatalf("Check() error = %v, want *RetryExhaustedError", err)
+	}
+	if re.Attempts != 2 || calls.Load() != 2 {
+		t.Errorf("Attempts = %d with %d calls, want 2", re.Attempts, calls.Load())
+	}
+	if re.TotalDuration < retryBaseDelay {
+		t.Errorf("TotalDuration = %v, want at least the %v backoff", re.TotalDuration, retryBaseDelay)
+	}
+	var se *statusError
+	if !errors.As(err, &se) || se.status != http.StatusBadGateway {
+		t.Errorf("Check() error = %v, want it to wrap the final 502", err)
+	}
+
+	// A non-retryable failure is not reported as exhausted
+	client, err = New(WithBackend(server.URL), WithRetryPredicate(func(*http.Response, error) bool { return false }))
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	_, err = client.Check(context.Background(), "https://github.com/owner/repo/pull/1", "alice", time.Now())
+	if err == nil || errors.As(err, &re) {
+		t.Errorf("Check() error = %v, want a plain API error", err)
+	}
+}
+
+func TestWithRetryDeadline(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	const deadline = 250 * time.Millisecond
+	client, err := New(WithBackend(server.URL), WithRetryAttempts(20), WithRetryDeadline(deadline))
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	start := time.Now()
+	_, err = client.Check(context.Background(), "https://github.com/owner/repo/pull/1", "alice", time.Now())
+	elapsed := time.Since(start)
+
+	if err == nil || !strings.Contains(err.Error(), "retry deadline of 250ms exceeded") {
+		t.Fatalf("Check() error = %v, want retry deadline error", err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Check() error = %v, want it to wrap context.DeadlineExceeded", err)
+	}
+	if elapsed > deadline+time.Second {
+		t.Errorf("Check() took %v, want about %v", elapsed, deadline)
+	}
+	if n := calls.Load(); n < 1 || n >= 20 {
+		t.Errorf("calls = %d, want the deadline to cut retries short", n)
+	}
+}
+
+func TestWithRetryDeadlineSuccess(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(CheckResponse{Commit: "abc"}); err != nil {
+			t.Errorf("failed to encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	client, err := New(WithBackend(server.URL), WithRetryDeadline(time.Second))
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	resp, err := client.Check(context.Background(), "https://github.com/owner/repo/pull/1", "alice", time.Now())
+	if err != nil {
+		t.Fatalf("Check() failed: %v", err)
+	}
+	if resp.Commit != "abc" {
+		t.Errorf("Commit = %q, want abc", resp.Commit)
+	}
+}
+
+func TestRetryNetworkErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		wantCalls int
+	}{
+		{name: "no such host", err: &net.DNSError{Err: "no such host", Name: "turn.invalid", IsNotFound: true}, wantCalls: 1},
+		{name: "DNS timeout", err: &net.DNSError{Err: "i/o timeout", Name: "turn.example.com", IsTimeout: true}, wantCalls: 2},
+		{name: "connection reset", err: &net.OpError{Op: "read", Net: "tcp", Err: errors.New("connection reset by peer")}, wantCalls: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := New(WithRetryAttempts(2), WithRetryRandSource(constSource(math.MaxUint64)))
+			if err != nil {
+				t.Fatalf("New() failed: %v", err)
+			}
+			var calls int
+			client.httpClient = &http.Client{Transport: roundTripFunc(func(*http.Request) (*http.Response, error) {
+				calls++
+				return nil, tt.err
+			})}
+
+			_, err = client.Check(context.Background(), "https://github.com/owner/repo/pull/1", "alice", time.Now())
+			if err == nil {
+				t.Fatal("Check() = nil, want error")
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("attempts = %d, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+// constSource is a rand.Source that always returns the same value.
+type constSource uint64
+
+func (s constSource) Uint64() uint64 { return uint64(s) }
+
+func TestWithRetryRandSource(t *testing.T) {
+	schedule := func(seed uint64) []time.Duration {
+		client, err := New(WithRetryRandSource(rand.NewPCG(seed, seed)))
+		if err != nil {
+			t.Fatalf("New() failed: %v", err)
+		}
+		var ds []time.Duration
+		for range 5 {
+			ds = append(ds, client.jitter())
+		}
+		return ds
+	}
+
+	a, b := schedule(42), schedule(42)
+	for i := range a {
+		if a[i] != b[i] {
+			t.Fatalf("jitter with the same seed differs: %v vs %v", a, b)
+		}
+		if a[i] < 0 || a[i] >= retryMaxJitter {
+			t.Errorf("jitter %v outside [0, %v)", a[i], retryMaxJitter)
+		}
+	}
+}
+
+func TestWithJitterStrategy(t *testing.T) {
+	cfg := &retry.Config{}
+	retry.Delay(retryBaseDelay)(cfg)
+
+	tests := []struct {
+		name     string
+		strategy JitterStrategy
+		// bounds returns the allowed [lo, hi) for the wait after backoff b
+		// when the previous wait was prev.
+		bounds func(b, prev time.Duration) (time.Duration, time.Duration)
+	}{
+		{"AdditiveJitter", AdditiveJitter, func(b, _ time.Duration) (time.Duration, time.Duration) { return b, b + retryMaxJitter }},
+		{"FullJitter", FullJitter, func(b, _ time.Duration) (time.Duration, time.Duration) { return 0, b }},
+		{"EqualJitter", EqualJitter, func(b, _ time.Duration) (time.Duration, time.Duration) { return b / 2, b }},
+		{"DecorrelatedJitter", DecorrelatedJitter, func(_, prev time.Duration) (time.Duration, time.Duration) {
+			return retryBaseDelay, 3 * max(prev, retryBaseDelay)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule := func(seed uint64) []time.Duration {
+				client, err := New(WithJitterStrategy(tt.strategy), WithRetryRandSource(rand.NewPCG(seed, seed)))
+				if err != nil {
+					t.Fatalf("New() failed: %v", err)
+				}
+				var ds []time.Duration
+				var prev time.Duration
+				for n := uint(1); n <= 8; n++ {
+					prev = client.retryDelay(n, errors.New("boom"), cfg, prev)
+					ds = append(ds, prev)
+				}
+				return ds
+			}
+
+			a, b := schedule(42), schedule(42)
+			var prev time.Duration
+			for i, d := range a {
+				if d != b[i] {
+					t.Fatalf("schedule with the same seed differs: %v vs %v", a, b)
+				}
+				lo, hi := tt.bounds(retryBaseDelay<<i, prev)
+				lo, hi = min(lo, retryMaxDelay), min(hi, retryMaxDelay+1)
+				if d < lo || d >= hi {
+					t.Errorf("wait %d = %v, want in [%v, %v)", i+1, d, lo, hi)
+				}
+				prev = d
+			}
+		})
+	}
+
+	if _, err := New(WithJitterStrategy(JitterStrategy(99))); err == nil {
+		t.Error("New() with an unknown jitter strategy succeeded, want error")
+	}
+}
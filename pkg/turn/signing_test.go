@@ -0,0 +1,61 @@
+package turn
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestWithRequestSigner(t *testing.T) {
+	secret := []byte("s3cret")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("failed to read body: %v", err)
+		}
+		ts := r.Header.Get("X-Timestamp")
+		if _, err := strconv.ParseInt(ts, 10, 64); err != nil {
+			t.Errorf("X-Timestamp = %q, want unix seconds", ts)
+		}
+
+		// Verify the way a server would, independently of signature()
+		mac := hmac.New(sha256.New, secret)
+		mac.Write([]byte(ts + "." + string(body)))
+		want := hex.EncodeToString(mac.Sum(nil))
+		if got := r.Header.Get("X-Signature"); !hmac.Equal([]byte(got), []byte(want)) {
+			t.Errorf("X-Signature = %q, want %q", got, want)
+		}
+
+		if err := json.NewEncoder(w).Encode(CheckResponse{}); err != nil {
+			t.Errorf("failed to encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	client, err := New(WithBackend(server.URL), WithRequestSigner(secret))
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	if _, err := client.Check(context.Background(), "https://github.com/owner/repo/pull/1", "alice", time.Now()); err != nil {
+		t.Fatalf("Check() failed: %v", err)
+	}
+	if _, err := client.CheckAll(context.Background(), "alice"); err != nil {
+		t.Fatalf("CheckAll() failed: %v", err)
+	}
+}
+
+func TestSignature(t *testing.T) {
+	// Fixed vector so server implementations can check their canonical string
+	got := signature([]byte("key"), "1700000000", []byte(`{"url":"x"}`))
+	if want := "11c5bcff4146ea43317bba1b500fcce36eab5d38156e2f0a74479c809895413c"; got != want {
+		t.Errorf("signature() = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,169 @@
+package turn
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// streamingKey marks a request context whose response body is consumed
+// incrementally, for as long as the caller's context allows.
+type streamingKey struct{}
+
+// headerTimeoutError reports that a streaming request got no response
+// headers within the HTTP client timeout.
+type headerTimeoutError struct {
+	timeout time.Duration
+}
+
+func (e headerTimeoutError) Error() string {
+	return fmt.Sprintf("no response headers within %v", e.timeout)
+}
+
+func (headerTimeoutError) Timeout() bool   { return true }
+func (headerTimeoutError) Temporary() bool { return true }
+
+// CheckStream checks several PRs in one request, calling fn with each result
+// as it arrives. It stops at the first error returned by fn, which is
+// returned as is, or when ctx is done.
+//
+// The endpoint is the validate path with "/stream" appended:
+//
+//	POST /v1/validate/stream [<CheckRequest>, ...]
+//	200 application/x-ndjson, one CheckResponse per line
+//
+// Results are decoded incrementally, so the stream is not subject to the
+// response size limit that applies to single checks. Nor is it cut off by the
+// HTTP client timeout, which only bounds the wait for the response to start;
+// reading the stream is bounded by ctx and WithRequestTimeout.
+func (c *Client) CheckStream(ctx context.Context, reqs []CheckRequest, fn func(*CheckResponse) error) error {
+	if fn == nil {
+		return errors.New("callback cannot be nil")
+	}
+	if len(reqs) == 0 {
+		return nil
+	}
+
+	body := make([]CheckRequest, len(reqs))
+	for i, r := range reqs {
+		if err := errors.Join(c.ValidateInput(r.URL, r.User, r.UpdatedAt), validateMetadata(r.Metadata)); err != nil {
+			return fmt.Errorf("request %d: %w", i, err)
+		}
+		r.UpdatedAt = r.UpdatedAt.UTC()
+		r.IncludeEvents = r.IncludeEvents || c.includeEvents
+		body[i] = r
+	}
+
+	ctx, cancel := c.withRequestTimeout(ctx)
+	defer cancel()
+
+	reqID := c.requestIDGen()
+	c.logf(reqID, "streaming checks for %d PRs", len(reqs))
+
+	data, err := encodeJSON(body)
+	if err != nil {
+		return fmt.Errorf("encode request: %w", err)
+	}
+
+	r, err := c.newRequest(context.WithValue(ctx, streamingKey{}, true), reqID, http.MethodPost, c.checkPath(c.validatePath+"/stream"), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	r.Header.Set("Accept", "application/x-ndjson")
+
+	c.logf(reqID, "sending request to %s", r.URL)
+	resp, err := c.doWithRetry(ctx, r)
+	if err != nil {
+		return fmt.Errorf("send request: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			c.logf(reqID, "failed to close response body: %v", err)
+		}
+	}()
+
+	c.logf(reqID, "received response: status=%d", resp.StatusCode)
+	if resp.StatusCode != http.StatusOK {
+		data, err := c.readBody(resp)
+		if err != nil {
+			return fmt.Errorf("read response: %w", err)
+		}
+		return newAPIError(resp.StatusCode, data, reqID)
+	}
+
+	rd, err := c.bodyReader(resp)
+	if err != nil {
+		return fmt.Errorf("read response: %w", err)
+	}
+	defer func() {
+		if err := rd.Close(); err != nil {
+			c.logf(reqID, "failed to close gzip reader: %v", err)
+		}
+	}()
+
+	dec := json.NewDecoder(rd)
+	if c.strict {
+		dec.DisallowUnknownFields()
+	}
+	n := 0
+	for {
+		if err := context.Cause(ctx); err != nil {
+			return err
+		}
+		var result CheckResponse
+		if err := dec.Decode(&result); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if ctxErr := context.Cause(ctx); ctxErr != nil {
+				return ctxErr
+			}
+			return fmt.Errorf("decode result %d: %w", n, err)
+		}
+		n++
+		if err := fn(&result); err != nil {
+			return err
+		}
+	}
+
+	c.logf(reqID, "stream complete: %d results", n)
+	return nil
+}
+
+// do sends a single attempt of req. The HTTP client timeout also covers
+// reading the body, so streaming requests are sent by a copy of the client
+// without it, and the timeout applies only until the headers arrive.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	timeout := c.httpClient.Timeout
+	if req.Context().Value(streamingKey{}) == nil || timeout <= 0 {
+		return c.httpClient.Do(req)
+	}
+
+	hc := *c.httpClient
+	hc.Timeout = 0
+	ctx, cancel := context.WithCancelCause(req.Context())
+	timer := time.AfterFunc(timeout, func() { cancel(headerTimeoutError{timeout}) })
+	resp, err := hc.Do(req.WithContext(ctx)) //nolint:bodyclose // closed by caller
+	if !timer.Stop() && err == nil {
+		// The headers raced the timer, which has already cancelled the body
+		if err := resp.Body.Close(); err != nil {
+			c.logf(requestIDOf(req), "failed to close response body: %v", err)
+		}
+		err = context.Cause(ctx)
+	}
+	if err != nil {
+		var headerErr headerTimeoutError
+		if errors.As(context.Cause(ctx), &headerErr) {
+			err = fmt.Errorf("%w: %w", headerErr, err)
+		}
+		cancel(nil)
+		return nil, err
+	}
+	resp.Body = &closeHook{ReadCloser: resp.Body, onClose: func() { cancel(nil) }}
+	return resp, nil
+}
@@ -0,0 +1,170 @@
+package turn
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func streamServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/validate/stream" {
+			http.NotFound(w, r)
+			return
+		}
+		if got := r.Header.Get("Accept"); got != "application/x-ndjson" {
+			t.Errorf("Accept = %q, want application/x-ndjson", got)
+		}
+		var reqs []CheckRequest
+		if err := json.NewDecoder(r.Body).Decode(&reqs); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		enc := json.NewEncoder(w)
+		for _, req := range reqs {
+			if err := enc.Encode(CheckResponse{Commit: req.URL}); err != nil {
+				t.Errorf("failed to encode response: %v", err)
+			}
+		}
+	}))
+}
+
+func TestCheckStream(t *testing.T) {
+	server := streamServer(t)
+	defer server.Close()
+
+	client, err := New(WithBackend(server.URL))
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+
+	now := time.Now()
+	reqs := []CheckRequest{
+		{URL: "https://github.com/owner/repo/pull/1", User: "alice", UpdatedAt: now},
+		{URL: "https://github.com/owner/repo/pull/2", User: "alice", UpdatedAt: now},
+		{URL: "https://github.com/owner/repo/pull/3", User: "alice", UpdatedAt: now},
+	}
+
+	var got []string
+	err = client.CheckStream(context.Background(), reqs, func(r *CheckResponse) error {
+		got = append(got, r.Commit)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("CheckStream() failed: %v", err)
+	}
+	if len(got) != 3 || got[0] != reqs[0].URL || got[2] != reqs[2].URL {
+		t.Errorf("results = %v, want one per request in order", got)
+	}
+
+	// A callback error stops the stream and is returned unchanged
+	errStop := errors.New("stop")
+	calls := 0
+	err = client.CheckStream(context.Background(), reqs, func(*CheckResponse) error {
+		calls++
+		return errStop
+	})
+	if !errors.Is(err, errStop) || calls != 1 {
+		t.Errorf("CheckStream() = %v after %d calls, want errStop after 1", err, calls)
+	}
+
+	// Invalid input is rejected before sending
+	bad := []CheckRequest{{URL: "not a url", User: "alice", UpdatedAt: now}}
+	if err := client.CheckStream(context.Background(), bad, func(*CheckResponse) error { return nil }); err == nil {
+		t.Error("CheckStream() with invalid URL should fail")
+	}
+}
+
+func TestCheckStreamCanceled(t *testing.T) {
+	server := streamServer(t)
+	defer server.Close()
+
+	client, err := New(WithBackend(server.URL))
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	reqs := []CheckRequest{
+		{URL: "https://github.com/owner/repo/pull/1", User: "alice", UpdatedAt: time.Now()},
+		{URL: "https://github.com/owner/repo/pull/2", User: "alice", UpdatedAt: time.Now()},
+	}
+	calls := 0
+	err = client.CheckStream(ctx, reqs, func(*CheckResponse) error {
+		calls++
+		cancel()
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) || calls != 1 {
+		t.Errorf("CheckStream() = %v after %d calls, want context.Canceled after 1", err, calls)
+	}
+}
+
+func TestCheckStreamOutlivesClientTimeout(t *testing.T) {
+	const timeout = 100 * time.Millisecond
+	tests := []struct {
+		name        string
+		headerDelay time.Duration // Before the response starts
+		bodyDelay   time.Duration // Between the first and second result
+		wantResults int
+		wantTimeout bool
+	}{
+		{name: "slow body", bodyDelay: 3 * timeout, wantResults: 2},
+		{name: "slow headers", headerDelay: 3 * timeout, wantTimeout: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				select {
+				case <-time.After(tt.headerDelay):
+				case <-r.Context().Done():
+					return
+				}
+				w.Header().Set("Content-Type", "application/x-ndjson")
+				enc := json.NewEncoder(w)
+				for i := range 2 {
+					if i > 0 {
+						w.(http.Flusher).Flush() //nolint:forcetypeassert // httptest writers flush
+						time.Sleep(tt.bodyDelay)
+					}
+					if err := enc.Encode(CheckResponse{}); err != nil {
+						t.Errorf("failed to encode response: %v", err)
+					}
+				}
+			}))
+			defer server.Close()
+
+			client, err := New(WithBackend(server.URL), WithRetryAttempts(1))
+			if err != nil {
+				t.Fatalf("New() failed: %v", err)
+			}
+			client.httpClient.Timeout = timeout
+
+			reqs := []CheckRequest{
+				{URL: "https://github.com/owner/repo/pull/1", User: "alice", UpdatedAt: time.Now()},
+				{URL: "https://github.com/owner/repo/pull/2", User: "alice", UpdatedAt: time.Now()},
+			}
+			results := 0
+			err = client.CheckStream(context.Background(), reqs, func(*CheckResponse) error {
+				results++
+				return nil
+			})
+			var netErr net.Error
+			timedOut := errors.As(err, &netErr) && netErr.Timeout()
+			if timedOut != tt.wantTimeout || (!tt.wantTimeout && err != nil) {
+				t.Errorf("CheckStream() error = %v, want header timeout %v", err, tt.wantTimeout)
+			}
+			if results != tt.wantResults {
+				t.Errorf("results = %d, want %d", results, tt.wantResults)
+			}
+		})
+	}
+}
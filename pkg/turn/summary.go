@@ -0,0 +1,55 @@
+package turn
+
+// RepoSummary aggregates the results of checking many PRs, e.g. every open PR
+// in a repository.
+type RepoSummary struct {
+	BlockedByUser     map[string]int        // Number of PRs with a pending action for each user
+	ByWorkflowState   map[WorkflowState]int // Number of PRs in each workflow state
+	Total             int                   // Number of responses summarized
+	ReadyToMergeCount int                   // Number of PRs ready to merge
+	TotalFailingTests int                   // Sum of failing checks across all PRs
+}
+
+// Summarize rolls up a set of check responses into a RepoSummary.
+// Nil responses are ignored.
+func Summarize(responses []*CheckResponse) RepoSummary {
+	s := RepoSummary{
+		BlockedByUser:   make(map[string]int),
+		ByWorkflowState: make(map[WorkflowState]int),
+	}
+	for _, r := range responses {
+		if r == nil {
+			continue
+		}
+		s.Total++
+		if r.Analysis.ReadyToMerge {
+			s.ReadyToMergeCount++
+		}
+		s.TotalFailingTests += r.Analysis.Checks.Failing
+		for user := range r.Analysis.NextAction {
+			s.BlockedByUser[user]++
+		}
+		if r.Analysis.WorkflowState != "" {
+			s.ByWorkflowState[WorkflowState(r.Analysis.WorkflowState)]++
+		}
+	}
+	return s
+}
+
+// ReviewerLoad counts, for each user, the responses on which they have a
+// review or re_review action, i.e. how many PRs are waiting on their review.
+// Other action kinds are not counted, and nil responses are ignored.
+func ReviewerLoad(responses []*CheckResponse) map[string]int {
+	load := make(map[string]int)
+	for _, r := range responses {
+		if r == nil {
+			continue
+		}
+		for user, a := range r.Analysis.NextAction {
+			if a.Kind == ActionReview || a.Kind == ActionReReview {
+				load[user]++
+			}
+		}
+	}
+	return load
+}
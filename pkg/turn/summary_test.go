@@ -0,0 +1,65 @@
+package turn
+
+import (
+	"maps"
+	"testing"
+)
+
+func TestSummarize(t *testing.T) {
+	responses := []*CheckResponse{
+		{Analysis: Analysis{
+			ReadyToMerge:  true,
+			WorkflowState: string(StateApprovedWaitingForMerge),
+			NextAction:    map[string]Action{"alice": {Kind: ActionMerge}},
+		}},
+		{Analysis: Analysis{
+			WorkflowState: string(StateTestedWaitingForFixes),
+			Checks:        Checks{Failing: 3},
+			NextAction:    map[string]Action{"alice": {Kind: ActionFixTests}, "bob": {Kind: ActionReview}},
+		}},
+		nil,
+		{Analysis: Analysis{
+			WorkflowState: string(StateTestedWaitingForFixes),
+			Checks:        Checks{Failing: 1},
+		}},
+	}
+
+	s := Summarize(responses)
+	if s.Total != 3 {
+		t.Errorf("Total = %d, want 3", s.Total)
+	}
+	if s.ReadyToMergeCount != 1 {
+		t.Errorf("ReadyToMergeCount = %d, want 1", s.ReadyToMergeCount)
+	}
+	if s.TotalFailingTests != 4 {
+		t.Errorf("TotalFailingTests = %d, want 4", s.TotalFailingTests)
+	}
+	if s.BlockedByUser["alice"] != 2 || s.BlockedByUser["bob"] != 1 {
+		t.Errorf("BlockedByUser = %v, want alice:2 bob:1", s.BlockedByUser)
+	}
+	if s.ByWorkflowState[StateTestedWaitingForFixes] != 2 || s.ByWorkflowState[StateApprovedWaitingForMerge] != 1 {
+		t.Errorf("ByWorkflowState = %v", s.ByWorkflowState)
+	}
+
+	empty := Summarize(nil)
+	if empty.Total != 0 || empty.BlockedByUser == nil || empty.ByWorkflowState == nil {
+		t.Errorf("Summarize(nil) = %+v, want zero counts with non-nil maps", empty)
+	}
+}
+
+func TestReviewerLoad(t *testing.T) {
+	responses := []*CheckResponse{
+		{Analysis: Analysis{NextAction: map[string]Action{"alice": {Kind: ActionReview}, "bob": {Kind: ActionFixTests}}}},
+		{Analysis: Analysis{NextAction: map[string]Action{"alice": {Kind: ActionReReview}, "carol": {Kind: ActionReview}}}},
+		{Analysis: Analysis{}},
+		nil,
+	}
+	got := ReviewerLoad(responses)
+	want := map[string]int{"alice": 2, "carol": 1}
+	if !maps.Equal(got, want) {
+		t.Errorf("ReviewerLoad() = %v, want %v", got, want)
+	}
+	if got := ReviewerLoad(nil); len(got) != 0 {
+		t.Errorf("ReviewerLoad(nil) = %v, want empty", got)
+	}
+}
@@ -0,0 +1,64 @@
+package turn
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+)
+
+// WithRootCAs sets the certificate authorities the client trusts for HTTPS
+// connections, replacing the system roots. Use it behind a TLS-intercepting
+// proxy rather than disabling certificate verification.
+func WithRootCAs(pool *x509.CertPool) Option {
+	return func(c *Client) {
+		if c.transport.TLSClientConfig == nil {
+			c.transport.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+		}
+		c.transport.TLSClientConfig.RootCAs = pool
+	}
+}
+
+// WithRootCAFile is WithRootCAs for a PEM bundle on disk. New fails if the
+// file cannot be read or contains no certificates.
+func WithRootCAFile(path string) Option {
+	return func(c *Client) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			c.configErrs = append(c.configErrs, fmt.Errorf("read root CA file: %w", err))
+			return
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			c.configErrs = append(c.configErrs, fmt.Errorf("root CA file %s contains no PEM certificates", path))
+			return
+		}
+		WithRootCAs(pool)(c)
+	}
+}
+
+// WithInsecureSkipVerify disables TLS certificate verification.
+//
+// WARNING: this makes HTTPS connections trivially interceptable. It exists
+// only for pointing the client at a local backend with a self-signed
+// certificate; never enable it in production. Prefer WithRootCAFile, which
+// trusts a specific certificate instead of any certificate. The client logs a
+// warning before its first request while this is enabled.
+func WithInsecureSkipVerify(skip bool) Option {
+	return func(c *Client) {
+		if c.transport.TLSClientConfig == nil {
+			c.transport.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+		}
+		c.transport.TLSClientConfig.InsecureSkipVerify = skip //nolint:gosec // opt-in, for local development only
+		c.insecure = skip
+	}
+}
+
+// warnInsecure logs, once per client, that certificate verification is off.
+func (c *Client) warnInsecure() {
+	if c.insecure {
+		c.insecureOnce.Do(func() {
+			c.logf("", "WARNING: TLS certificate verification is disabled; use only for local development")
+		})
+	}
+}
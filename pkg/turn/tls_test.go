@@ -0,0 +1,100 @@
+package turn
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithRootCAFile(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(CheckResponse{}); err != nil {
+			t.Errorf("failed to encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "ca.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caFile, certPEM, 0o600); err != nil {
+		t.Fatalf("failed to write CA file: %v", err)
+	}
+
+	const prURL = "https://github.com/owner/repo/pull/1"
+	ctx := context.Background()
+
+	untrusting, err := New(WithBackend(server.URL), WithoutRetries())
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	if _, err := untrusting.Check(ctx, prURL, "alice", time.Now()); err == nil {
+		t.Error("Check() without the CA = nil, want certificate error")
+	}
+
+	trusting, err := New(WithBackend(server.URL), WithRootCAFile(caFile))
+	if err != nil {
+		t.Fatalf("New(WithRootCAFile) failed: %v", err)
+	}
+	if _, err := trusting.Check(ctx, prURL, "alice", time.Now()); err != nil {
+		t.Errorf("Check() with the CA failed: %v", err)
+	}
+}
+
+func TestWithRootCAFileErrors(t *testing.T) {
+	dir := t.TempDir()
+	empty := filepath.Join(dir, "empty.pem")
+	if err := os.WriteFile(empty, []byte("not a certificate\n"), 0o600); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		wantErr string
+	}{
+		{name: "missing file", path: filepath.Join(dir, "missing.pem"), wantErr: "read root CA file"},
+		{name: "no certificates", path: empty, wantErr: "contains no PEM certificates"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(WithRootCAFile(tt.path))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("New() error = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWithInsecureSkipVerify(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(CheckResponse{}); err != nil {
+			t.Errorf("failed to encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	var logs strings.Builder
+	client, err := New(WithBackend(server.URL), WithInsecureSkipVerify(true), WithLogger(log.New(&logs, "", 0)))
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	for range 2 {
+		if _, err := client.Check(context.Background(), "https://github.com/owner/repo/pull/1", "alice", time.Now()); err != nil {
+			t.Fatalf("Check() with verification disabled failed: %v", err)
+		}
+	}
+	if n := strings.Count(logs.String(), "TLS certificate verification is disabled"); n != 1 {
+		t.Errorf("insecure warning logged %d times, want 1:\n%s", n, logs.String())
+	}
+}
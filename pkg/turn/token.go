@@ -0,0 +1,189 @@
+package turn
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// TokenSource supplies auth tokens along with their expiry.
+// A zero expiry means the token's lifetime is unknown, so the client will
+// ask the source again before every request.
+type TokenSource interface {
+	Token(ctx context.Context) (token string, expiry time.Time, err error)
+}
+
+// tokenFunc adapts a function without expiry information to a TokenSource.
+type tokenFunc func(ctx context.Context) (string, error)
+
+func (f tokenFunc) Token(ctx context.Context) (string, time.Time, error) {
+	token, err := f(ctx)
+	return token, time.Time{}, err
+}
+
+// WithTokenSource sets a function that is called before each request to obtain
+// a fresh auth token, such as a short-lived GitHub App installation token.
+// When set, it takes precedence over any static token from WithAuthToken.
+func WithTokenSource(fn func(ctx context.Context) (string, error)) Option {
+	return func(c *Client) {
+		c.tokenSource = tokenFunc(fn)
+	}
+}
+
+// WithExpiringTokenSource sets a TokenSource whose tokens are cached until they
+// come within the refresh skew of their expiry.
+// When set, it takes precedence over any static token from WithAuthToken.
+func WithExpiringTokenSource(ts TokenSource) Option {
+	return func(c *Client) {
+		c.tokenSource = ts
+	}
+}
+
+// WithTokenRefreshSkew sets how long before expiry a cached token is refreshed.
+// The default is 60 seconds.
+func WithTokenRefreshSkew(d time.Duration) Option {
+	return func(c *Client) {
+		if d >= 0 {
+			c.tokenSkew = d
+		}
+	}
+}
+
+// contextTokenKey is the context key for WithContextToken.
+type contextTokenKey struct{}
+
+// WithContextToken returns a copy of ctx carrying token, which Check,
+// CurrentUser and the client's other requests made with that context use in
+// place of the client's own token or token source. It is meant for
+// per-request overrides, such as a user's OAuth token in an HTTP handler,
+// without cloning the client. Cached responses are kept separately for each
+// context token. An empty token is ignored.
+func WithContextToken(ctx context.Context, token string) context.Context {
+	return context.WithValue(ctx, contextTokenKey{}, token)
+}
+
+// contextToken returns the token set by WithContextToken, if any.
+func contextToken(ctx context.Context) string {
+	t, _ := ctx.Value(contextTokenKey{}).(string)
+	return t
+}
+
+// staticToken returns the token set by WithAuthToken or SetAuthToken.
+func (c *Client) staticToken() string {
+	if t := c.authToken.Load(); t != nil {
+		return *t
+	}
+	return ""
+}
+
+// token returns the auth token to use for a request: the context token if
+// set, otherwise the static token or, if one is configured, the token source
+// when the cached token is missing or near expiry.
+func (c *Client) token(ctx context.Context) (string, error) {
+	if t := contextToken(ctx); t != "" {
+		return t, nil
+	}
+	if c.tokenSource == nil {
+		return c.staticToken(), nil
+	}
+
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+
+	now := c.now()
+	if c.cachedToken != "" && !c.tokenExpiry.IsZero() && now.Add(c.tokenSkew).Before(c.tokenExpiry) {
+		return c.cachedToken, nil
+	}
+
+	token, expiry, err := c.tokenSource.Token(ctx)
+	if err != nil {
+		return "", fmt.Errorf("fetch auth token: %w", err)
+	}
+	if !expiry.IsZero() && !now.Before(expiry) {
+		return "", errors.New("token source returned an expired token")
+	}
+
+	c.cachedToken = token
+	c.tokenExpiry = expiry
+	if !expiry.IsZero() {
+		c.logf("", "refreshed auth token, expires at %s", expiry.Format(time.RFC3339))
+	}
+	return token, nil
+}
+
+// invalidateToken drops the cached token if it is still token, so the next
+// call to token asks the source for a new one.
+func (c *Client) invalidateToken(token string) {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+	if c.cachedToken == token {
+		c.cachedToken = ""
+		c.tokenExpiry = time.Time{}
+	}
+}
+
+// retryWithFreshToken handles a 401 for a request authenticated by the token
+// source. The token may have expired between minting and use, so it is
+// dropped, a new one is fetched, and the request is sent one more time. A
+// second 401 is returned to the caller as is.
+func (c *Client) retryWithFreshToken(ctx context.Context, req *http.Request, resp *http.Response) (*http.Response, error) {
+	if req.GetBody == nil && req.Body != nil && req.Body != http.NoBody {
+		return resp, nil // The body cannot be replayed
+	}
+	if _, err := io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseSize)); err != nil {
+		c.logf(requestIDOf(req), "failed to drain response body: %v", err)
+	}
+	if err := resp.Body.Close(); err != nil {
+		c.logf(requestIDOf(req), "failed to close response body: %v", err)
+	}
+
+	c.invalidateToken(c.requestToken(req))
+	token, err := c.token(ctx)
+	if err != nil {
+		return nil, err
+	}
+	retry := req.Clone(ctx)
+	if req.GetBody != nil {
+		if retry.Body, err = req.GetBody(); err != nil {
+			return nil, fmt.Errorf("rewind request body: %w", err)
+		}
+	}
+	if c.isTurnRequest(req) {
+		c.setToken(retry, token)
+		c.setAuthHeaders(retry)
+	} else {
+		// CurrentUser's GitHub request carries only the bearer token; gateway
+		// headers must not leak to GitHub.
+		retry.Header.Set("Authorization", "Bearer "+token)
+		c.sentToken.Store(&token)
+	}
+
+	c.logf(requestIDOf(req), "got 401, retrying once with a refreshed auth token")
+	return c.retryLoop(ctx, retry)
+}
+
+// isTurnRequest reports whether req goes to the Turn API rather than GitHub.
+func (c *Client) isTurnRequest(req *http.Request) bool {
+	return strings.HasPrefix(req.URL.String(), c.baseURL+"/")
+}
+
+// requestToken returns the GitHub token setToken put on req. When an
+// Authorization header from WithAuthHeader replaced the bearer token, the
+// request no longer carries it, so the last token sent is used instead.
+// Requests to GitHub always carry it as a bearer token.
+func (c *Client) requestToken(req *http.Request) string {
+	switch {
+	case !c.isTurnRequest(req):
+		return strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+	case c.tokenHeader != "":
+		return req.Header.Get(c.tokenHeader)
+	case c.authHeaders.Get("Authorization") == "":
+		return strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+	default:
+		return c.lastSentToken()
+	}
+}
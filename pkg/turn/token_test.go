@@ -0,0 +1,421 @@
+package turn
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWithTokenSource(t *testing.T) {
+	var seen []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = append(seen, r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(CheckResponse{}); err != nil {
+			t.Errorf("failed to encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	calls := 0
+	client, err := New(
+		WithBackend(server.URL),
+		WithAuthToken("static-token"),
+		WithTokenSource(func(context.Context) (string, error) {
+			calls++
+			return fmt.Sprintf("token-%d", calls), nil
+		}),
+	)
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+
+	ctx := context.Background()
+	for range 2 {
+		if _, err := client.Check(ctx, "https://github.com/owner/repo/pull/123", "testuser", time.Now()); err != nil {
+			t.Fatalf("Check() failed: %v", err)
+		}
+	}
+
+	want := []string{"Bearer token-1", "Bearer token-2"}
+	if !slices.Equal(seen, want) {
+		t.Errorf("Authorization headers = %v, want %v", seen, want)
+	}
+
+	t.Run("source error", func(t *testing.T) {
+		client, err := New(
+			WithBackend(server.URL),
+			WithTokenSource(func(context.Context) (string, error) {
+				return "", errors.New("mint failed")
+			}),
+		)
+		if err != nil {
+			t.Fatalf("New() failed: %v", err)
+		}
+		_, err = client.Check(ctx, "https://github.com/owner/repo/pull/123", "testuser", time.Now())
+		if err == nil || !strings.Contains(err.Error(), "mint failed") {
+			t.Errorf("expected token source error, got %v", err)
+		}
+	})
+}
+
+type fakeTokenSource struct {
+	mu     sync.Mutex
+	calls  int
+	expiry func(now time.Time) time.Time
+	err    error
+}
+
+func (f *fakeTokenSource) Token(context.Context) (string, time.Time, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return "", time.Time{}, f.err
+	}
+	f.calls++
+	return fmt.Sprintf("token-%d", f.calls), f.expiry(time.Now()), nil
+}
+
+func TestExpiringTokenSource(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("cached until near expiry", func(t *testing.T) {
+		ts := &fakeTokenSource{expiry: func(now time.Time) time.Time { return now.Add(time.Hour) }}
+		client, err := New(WithExpiringTokenSource(ts))
+		if err != nil {
+			t.Fatalf("New() failed: %v", err)
+		}
+		for range 3 {
+			tok, err := client.token(ctx)
+			if err != nil {
+				t.Fatalf("token() failed: %v", err)
+			}
+			if tok != "token-1" {
+				t.Errorf("token() = %s, want token-1", tok)
+			}
+		}
+		if ts.calls != 1 {
+			t.Errorf("source called %d times, want 1", ts.calls)
+		}
+	})
+
+	t.Run("refreshed within skew", func(t *testing.T) {
+		ts := &fakeTokenSource{expiry: func(now time.Time) time.Time { return now.Add(30 * time.Second) }}
+		client, err := New(WithExpiringTokenSource(ts))
+		if err != nil {
+			t.Fatalf("New() failed: %v", err)
+		}
+		for range 2 {
+			if _, err := client.token(ctx); err != nil {
+				t.Fatalf("token() failed: %v", err)
+			}
+		}
+		if ts.calls != 2 {
+			t.Errorf("source called %d times, want 2 (default skew is 60s)", ts.calls)
+		}
+
+		ts.calls = 0
+		client, err = New(WithExpiringTokenSource(ts), WithTokenRefreshSkew(10*time.Second))
+		if err != nil {
+			t.Fatalf("New() failed: %v", err)
+		}
+		for range 2 {
+			if _, err := client.token(ctx); err != nil {
+				t.Fatalf("token() failed: %v", err)
+			}
+		}
+		if ts.calls != 1 {
+			t.Errorf("source called %d times, want 1 with 10s skew", ts.calls)
+		}
+	})
+
+	t.Run("expired token rejected", func(t *testing.T) {
+		ts := &fakeTokenSource{expiry: func(now time.Time) time.Time { return now.Add(-time.Minute) }}
+		client, err := New(WithExpiringTokenSource(ts))
+		if err != nil {
+			t.Fatalf("New() failed: %v", err)
+		}
+		if _, err := client.token(ctx); err == nil || !strings.Contains(err.Error(), "expired") {
+			t.Errorf("expected expired token error, got %v", err)
+		}
+	})
+
+	t.Run("concurrent use", func(t *testing.T) {
+		ts := &fakeTokenSource{expiry: func(now time.Time) time.Time { return now.Add(time.Hour) }}
+		client, err := New(WithExpiringTokenSource(ts))
+		if err != nil {
+			t.Fatalf("New() failed: %v", err)
+		}
+		var wg sync.WaitGroup
+		for range 10 {
+			wg.Go(func() {
+				if _, err := client.token(ctx); err != nil {
+					t.Errorf("token() failed: %v", err)
+				}
+			})
+		}
+		wg.Wait()
+		if ts.calls != 1 {
+			t.Errorf("source called %d times, want 1", ts.calls)
+		}
+	})
+}
+
+func TestSetAuthTokenConcurrent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer token-") {
+			t.Errorf("unexpected Authorization header %q", r.Header.Get("Authorization"))
+		}
+		if err := json.NewEncoder(w).Encode(CheckResponse{}); err != nil {
+			t.Errorf("failed to encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	client, err := New(WithBackend(server.URL), WithAuthToken("token-0"), WithNoCache(true))
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	for i := range 5 {
+		wg.Go(func() {
+			for j := range 20 {
+				client.SetAuthToken(fmt.Sprintf("token-%d-%d", i, j))
+			}
+		})
+		wg.Go(func() {
+			for range 5 {
+				if _, err := client.Check(ctx, "https://github.com/owner/repo/pull/1", "alice", time.Now()); err != nil {
+					t.Errorf("Check() failed: %v", err)
+				}
+			}
+		})
+	}
+	wg.Wait()
+}
+
+func TestWithContextToken(t *testing.T) {
+	var got []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(CheckResponse{}); err != nil {
+			t.Errorf("failed to encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	client, err := New(WithBackend(server.URL), WithAuthToken("client-token"), WithNoCache(true))
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	client.httpClient.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Host == "api.github.com" {
+			got = append(got, req.Header.Get("Authorization"))
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(`{"login":"octocat"}`)),
+				Header:     make(http.Header),
+				Request:    req,
+			}, nil
+		}
+		return http.DefaultTransport.RoundTrip(req)
+	})
+
+	const prURL = "https://github.com/owner/repo/pull/1"
+	ctx := WithContextToken(context.Background(), "user-token")
+	if _, err := client.Check(ctx, prURL, "alice", time.Now()); err != nil {
+		t.Fatalf("Check() with context token failed: %v", err)
+	}
+	if _, err := client.CurrentUser(ctx); err != nil {
+		t.Fatalf("CurrentUser() with context token failed: %v", err)
+	}
+	if _, err := client.Check(context.Background(), prURL, "alice", time.Now()); err != nil {
+		t.Fatalf("Check() without context token failed: %v", err)
+	}
+
+	want := []string{"Bearer user-token", "Bearer user-token", "Bearer client-token"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Authorization headers = %q, want %q", got, want)
+	}
+}
+
+func TestContextTokenCacheIsolation(t *testing.T) {
+	var got []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "max-age=60")
+		if err := json.NewEncoder(w).Encode(CheckResponse{}); err != nil {
+			t.Errorf("failed to encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	client, err := New(WithBackend(server.URL))
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+
+	const prURL = "https://github.com/owner/repo/pull/1"
+	updatedAt := time.Now()
+	for _, token := range []string{"alice-token", "mallory-token", "alice-token"} {
+		ctx := WithContextToken(context.Background(), token)
+		if _, err := client.Check(ctx, prURL, "alice", updatedAt); err != nil {
+			t.Fatalf("Check() with %s failed: %v", token, err)
+		}
+	}
+
+	// The repeat alice-token check is served from the cache; mallory-token's is not
+	want := []string{"Bearer alice-token", "Bearer mallory-token"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Authorization per request = %q, want %q", got, want)
+	}
+}
+
+func TestRefreshTokenOn401(t *testing.T) {
+	tests := []struct {
+		name      string
+		valid     string // Token the server accepts; "" rejects all
+		wantCalls int
+		wantErr   error
+	}{
+		{name: "expired in flight", valid: "token-2", wantCalls: 2},
+		{name: "still rejected after refresh", wantCalls: 2, wantErr: ErrUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				if tt.valid == "" || r.Header.Get("Authorization") != "Bearer "+tt.valid {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				w.Header().Set("Content-Type", "application/json")
+				if err := json.NewEncoder(w).Encode(CheckResponse{}); err != nil {
+					t.Errorf("failed to encode response: %v", err)
+				}
+			}))
+			defer server.Close()
+
+			var minted int
+			source := func(context.Context) (string, error) {
+				minted++
+				return fmt.Sprintf("token-%d", minted), nil
+			}
+			client, err := New(WithBackend(server.URL), WithTokenSource(source))
+			if err != nil {
+				t.Fatalf("New() failed: %v", err)
+			}
+
+			_, err = client.Check(context.Background(), "https://github.com/owner/repo/pull/1", "alice", time.Now())
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("Check() failed: %v", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Check() error = %v, want %v", err, tt.wantErr)
+			}
+			if calls != tt.wantCalls || minted != 2 {
+				t.Errorf("requests = %d, tokens minted = %d, want %d and 2", calls, minted, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestRefreshTokenOn401WithAuthHeader(t *testing.T) {
+	var auths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auths = append(auths, r.Header.Get("Authorization"))
+		if len(auths) == 1 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(CheckResponse{}); err != nil {
+			t.Errorf("failed to encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	var minted int
+	source := func(context.Context) (string, error) {
+		minted++
+		return fmt.Sprintf("token-%d", minted), nil
+	}
+	client, err := New(WithBackend(server.URL), WithTokenSource(source), WithAuthHeader("Authorization", "ApiKey gateway-key"))
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+
+	if _, err := client.Check(context.Background(), "https://github.com/owner/repo/pull/1", "alice", time.Now()); err != nil {
+		t.Fatalf("Check() failed: %v", err)
+	}
+	if want := []string{"ApiKey gateway-key", "ApiKey gateway-key"}; !slices.Equal(auths, want) {
+		t.Errorf("Authorization per attempt = %q, want %q", auths, want)
+	}
+	if minted != 2 {
+		t.Errorf("tokens minted = %d, want 2 (the rejected token should be invalidated)", minted)
+	}
+}
+
+func TestRefreshTokenOn401CurrentUserWithBasicAuth(t *testing.T) {
+	var sent []http.Header
+	var minted int
+	source := func(context.Context) (string, error) {
+		minted++
+		return fmt.Sprintf("token-%d", minted), nil
+	}
+	client, err := New(WithTokenSource(source), WithBasicAuth("gw-user", "gw-pass"), WithAuthHeader("X-Api-Key", "gateway-key"))
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	client.httpClient.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		sent = append(sent, req.Header.Clone())
+		status, body := http.StatusOK, `{"login":"octocat"}`
+		if len(sent) == 1 {
+			status, body = http.StatusUnauthorized, `{"message":"Bad credentials"}`
+		}
+		return &http.Response{
+			StatusCode: status,
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Header:     make(http.Header),
+			Request:    req,
+		}, nil
+	})
+
+	user, err := client.CurrentUser(context.Background())
+	if err != nil {
+		t.Fatalf("CurrentUser() failed: %v", err)
+	}
+	if user != "octocat" {
+		t.Errorf("CurrentUser() = %q, want octocat", user)
+	}
+	if len(sent) != 2 {
+		t.Fatalf("requests = %d, want 2", len(sent))
+	}
+	for i, h := range sent {
+		if got, want := h.Get("Authorization"), fmt.Sprintf("Bearer token-%d", i+1); got != want {
+			t.Errorf("attempt %d: Authorization = %q, want %q", i+1, got, want)
+		}
+		for _, name := range []string{githubTokenHeader, "X-Api-Key"} {
+			if v := h.Get(name); v != "" {
+				t.Errorf("attempt %d: gateway header %s = %q sent to GitHub", i+1, name, v)
+			}
+		}
+	}
+	if minted != 2 {
+		t.Errorf("tokens minted = %d, want 2 (the rejected token should be invalidated)", minted)
+	}
+}
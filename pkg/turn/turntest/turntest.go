@@ -0,0 +1,86 @@
+// Package turntest provides test doubles for code that uses the turn package.
+package turntest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"github.com/codeGROOVE-dev/turnclient/pkg/turn"
+)
+
+// NewServer starts an httptest.Server that mimics the Turn API's /v1/validate
+// endpoint, answering each check with the response registered for its PR URL.
+// Unknown URLs receive a 404. The caller must Close the server.
+func NewServer(responses map[string]turn.CheckResponse) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/validate", func(w http.ResponseWriter, r *http.Request) {
+		var req turn.CheckRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, fmt.Sprintf("invalid request: %v", err), http.StatusBadRequest)
+			return
+		}
+		resp, ok := responses[req.URL]
+		if !ok {
+			http.Error(w, "no response registered for "+req.URL, http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+	return httptest.NewServer(mux)
+}
+
+// FakeClient is an in-memory turn.Checker.
+// It is safe for concurrent use.
+type FakeClient struct {
+	Responses map[string]*turn.CheckResponse // Keyed by PR URL
+	Err       error                          // If set, returned from every call
+	User      string                         // Login returned by CurrentUser
+
+	mu    sync.Mutex
+	calls []turn.CheckRequest
+}
+
+var _ turn.Checker = (*FakeClient)(nil)
+
+// Check returns the response registered for prURL, or an error if there is none.
+func (f *FakeClient) Check(_ context.Context, prURL, user string, updatedAt time.Time) (*turn.CheckResponse, error) {
+	f.mu.Lock()
+	f.calls = append(f.calls, turn.CheckRequest{URL: prURL, User: user, UpdatedAt: updatedAt})
+	f.mu.Unlock()
+
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	resp, ok := f.Responses[prURL]
+	if !ok {
+		return nil, fmt.Errorf("turntest: no response registered for %s", prURL)
+	}
+	return resp, nil
+}
+
+// CurrentUser returns the configured User, or an error if it is empty.
+func (f *FakeClient) CurrentUser(context.Context) (string, error) {
+	if f.Err != nil {
+		return "", f.Err
+	}
+	if f.User == "" {
+		return "", errors.New("turntest: no user configured")
+	}
+	return f.User, nil
+}
+
+// Calls returns the checks made so far, in order.
+func (f *FakeClient) Calls() []turn.CheckRequest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]turn.CheckRequest(nil), f.calls...)
+}
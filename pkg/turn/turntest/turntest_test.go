@@ -0,0 +1,73 @@
+package turntest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/codeGROOVE-dev/turnclient/pkg/turn"
+)
+
+const prURL = "https://github.com/owner/repo/pull/123"
+
+func TestNewServer(t *testing.T) {
+	server := NewServer(map[string]turn.CheckResponse{
+		prURL: {Commit: "abc", Analysis: turn.Analysis{ReadyToMerge: true}},
+	})
+	defer server.Close()
+
+	client, err := turn.NewClient(server.URL)
+	if err != nil {
+		t.Fatalf("NewClient() failed: %v", err)
+	}
+
+	ctx := context.Background()
+	result, err := client.Check(ctx, prURL, "alice", time.Now())
+	if err != nil {
+		t.Fatalf("Check() failed: %v", err)
+	}
+	if result.Commit != "abc" || !result.Analysis.ReadyToMerge {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	_, err = client.Check(ctx, "https://github.com/owner/repo/pull/999", "alice", time.Now())
+	var apiErr *turn.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != 404 {
+		t.Errorf("expected 404 APIError for unknown URL, got %v", err)
+	}
+}
+
+func TestFakeClient(t *testing.T) {
+	fake := &FakeClient{
+		Responses: map[string]*turn.CheckResponse{prURL: {Commit: "abc"}},
+	}
+
+	var checker turn.Checker = fake
+	ctx := context.Background()
+	result, err := checker.Check(ctx, prURL, "alice", time.Now())
+	if err != nil || result.Commit != "abc" {
+		t.Errorf("Check() = %v, %v; want commit abc", result, err)
+	}
+	if _, err := checker.Check(ctx, "https://github.com/owner/repo/pull/999", "bob", time.Now()); err == nil {
+		t.Error("expected error for unregistered URL")
+	}
+
+	calls := fake.Calls()
+	if len(calls) != 2 || calls[0].User != "alice" || calls[1].User != "bob" {
+		t.Errorf("Calls() = %+v", calls)
+	}
+
+	if _, err := checker.CurrentUser(ctx); err == nil {
+		t.Error("expected error when no user configured")
+	}
+	fake.User = "alice"
+	if login, err := checker.CurrentUser(ctx); err != nil || login != "alice" {
+		t.Errorf("CurrentUser() = %q, %v; want alice", login, err)
+	}
+
+	fake.Err = errors.New("boom")
+	if _, err := checker.Check(ctx, prURL, "alice", time.Now()); !errors.Is(err, fake.Err) {
+		t.Errorf("expected configured error, got %v", err)
+	}
+}
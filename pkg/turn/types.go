@@ -1,6 +1,8 @@
 package turn
 
 import (
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/codeGROOVE-dev/prx/pkg/prx"
@@ -42,12 +44,50 @@ const (
 	StateApprovedWaitingForMerge    WorkflowState = "APPROVED_WAITING_FOR_MERGE"
 )
 
+// PRSize represents the size category of a PR.
+type PRSize string
+
+// PR size constants, from smallest to largest.
+const (
+	SizeXS PRSize = "XS"
+	SizeS  PRSize = "S"
+	SizeM  PRSize = "M"
+	SizeL  PRSize = "L"
+	SizeXL PRSize = "XL"
+)
+
+// sizeRank orders known sizes; unknown sizes have rank 0.
+var sizeRank = map[PRSize]int{SizeXS: 1, SizeS: 2, SizeM: 3, SizeL: 4, SizeXL: 5}
+
+// ParsePRSize parses a size category such as "M" or "xl".
+func ParsePRSize(s string) (PRSize, error) {
+	size := PRSize(strings.ToUpper(strings.TrimSpace(s)))
+	if _, ok := sizeRank[size]; !ok {
+		return "", fmt.Errorf("unknown PR size %q", s)
+	}
+	return size, nil
+}
+
+// GreaterThan reports whether s is a larger size category than other.
+// It returns false if either size is unknown.
+func (s PRSize) GreaterThan(other PRSize) bool {
+	a, b := sizeRank[s], sizeRank[other]
+	return a > 0 && b > 0 && a > b
+}
+
 // CheckRequest represents a request to check if a PR is blocked by a user.
 type CheckRequest struct {
-	URL           string    `json:"url"`
-	UpdatedAt     time.Time `json:"updated_at"` // Last known update time of the PR (required)
-	User          string    `json:"user"`
-	IncludeEvents bool      `json:"include_events,omitempty"` // Include full event list from prx (defaults to false)
+	URL           string            `json:"url"`
+	UpdatedAt     time.Time         `json:"updated_at"` // Last known update time of the PR (required)
+	User          string            `json:"user"`
+	IncludeEvents bool              `json:"include_events,omitempty"` // Include full event list from prx (defaults to false)
+	Commit        string            `json:"commit,omitempty"`         // Known head SHA; if it matches the server's, cached analysis may be served
+	Metadata      map[string]string `json:"metadata,omitempty"`       // Extra context for the analysis, such as team or priority
+}
+
+// options returns the per-request parameters of r for CheckWithOptions.
+func (r CheckRequest) options() CheckOptions {
+	return CheckOptions{Commit: r.Commit, Metadata: r.Metadata, IncludeEvents: r.IncludeEvents}
 }
 
 // Action represents an expected action from a specific user.
@@ -90,7 +130,7 @@ type Analysis struct {
 	LastActivity       LastActivity      `json:"last_activity"`
 	NextAction         map[string]Action `json:"next_action"`
 	SecondsInState     map[string]int    `json:"seconds_in_state,omitempty"`
-	Size               string            `json:"size"`
+	Size               PRSize            `json:"size"`
 	WorkflowState      string            `json:"workflow_state,omitempty"`
 	Tags               []string          `json:"tags"`
 	StateTransitions   []StateTransition `json:"state_transitions,omitempty"`
@@ -103,12 +143,13 @@ type Analysis struct {
 
 // CheckResponse represents the response from a PR check.
 type CheckResponse struct {
-	Timestamp            time.Time       `json:"timestamp"`
-	Commit               string          `json:"commit"`
-	Events               []prx.Event     `json:"events,omitempty"`
-	PullRequest          prx.PullRequest `json:"pull_request"`
-	Analysis             Analysis        `json:"analysis"`
-	Tier                 string          `json:"tier,omitempty"`                   // GitHub Marketplace tier (free/pro/flock), only set for GitHub
-	PrivateReposEnabled  bool            `json:"private_repos_enabled,omitempty"`  // Whether user can access private repos
-	TierEnforcementActive bool           `json:"tier_enforcement_active"`          // Whether tier restrictions are enforced
+	Timestamp             time.Time       `json:"timestamp"`
+	URL                   string          `json:"url,omitempty"` // PR URL; filled in from the request if the backend omits it
+	Commit                string          `json:"commit"`
+	Events                []prx.Event     `json:"events,omitempty"`
+	PullRequest           prx.PullRequest `json:"pull_request"`
+	Analysis              Analysis        `json:"analysis"`
+	Tier                  string          `json:"tier,omitempty"`                  // GitHub Marketplace tier (free/pro/flock), only set for GitHub
+	PrivateReposEnabled   bool            `json:"private_repos_enabled,omitempty"` // Whether user can access private repos
+	TierEnforcementActive bool            `json:"tier_enforcement_active"`         // Whether tier restrictions are enforced
 }
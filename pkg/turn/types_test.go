@@ -2,6 +2,7 @@ package turn
 
 import (
 	"encoding/json"
+	"strings"
 	"testing"
 	"time"
 )
@@ -30,6 +31,16 @@ func TestCheckRequestJSON(t *testing.T) {
 	}
 }
 
+func TestCheckRequestCommitOmitted(t *testing.T) {
+	data, err := json.Marshal(CheckRequest{URL: "https://github.com/owner/repo/pull/1"})
+	if err != nil {
+		t.Fatalf("failed to marshal CheckRequest: %v", err)
+	}
+	if strings.Contains(string(data), `"commit"`) {
+		t.Errorf("empty Commit was encoded: %s", data)
+	}
+}
+
 func TestCheckResponseJSON(t *testing.T) {
 	now := time.Now()
 	resp := CheckResponse{
@@ -103,3 +114,48 @@ func TestCheckResponseJSON(t *testing.T) {
 		t.Errorf("Commit = %s, want %s", decoded.Commit, resp.Commit)
 	}
 }
+
+func TestParsePRSize(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    PRSize
+		wantErr bool
+	}{
+		{in: "XS", want: SizeXS},
+		{in: "m", want: SizeM},
+		{in: " xl ", want: SizeXL},
+		{in: "XXL", wantErr: true},
+		{in: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParsePRSize(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParsePRSize(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Errorf("ParsePRSize(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestPRSizeGreaterThan(t *testing.T) {
+	if !SizeL.GreaterThan(SizeM) {
+		t.Error("L should be greater than M")
+	}
+	if SizeM.GreaterThan(SizeM) || SizeS.GreaterThan(SizeXL) {
+		t.Error("GreaterThan should be strict and ordered")
+	}
+	if PRSize("HUGE").GreaterThan(SizeXS) || SizeXL.GreaterThan(PRSize("")) {
+		t.Error("unknown sizes should never compare greater")
+	}
+}
+
+func TestPRSizeJSON(t *testing.T) {
+	var a Analysis
+	if err := json.Unmarshal([]byte(`{"size":"L"}`), &a); err != nil {
+		t.Fatalf("failed to unmarshal Analysis: %v", err)
+	}
+	if a.Size != SizeL {
+		t.Errorf("Size = %q, want L", a.Size)
+	}
+}
@@ -0,0 +1,43 @@
+package turn
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// ServerInfo describes the backend build serving requests.
+type ServerInfo struct {
+	BuildTime time.Time `json:"build_time"`
+	Commit    string    `json:"commit"`
+	Version   string    `json:"version"`
+}
+
+// ServerInfo reports which backend build is serving traffic, without running
+// a check. The backend is expected to implement:
+//
+//	GET /v1/version
+//	200 {"commit": "<sha>", "version": "<version>", "build_time": "<RFC3339>"}
+//
+// The path follows any prefix set with WithEndpointPath. Backends without the
+// endpoint respond with 404, which is reported as an error wrapping
+// ErrNotSupported.
+func (c *Client) ServerInfo(ctx context.Context) (ServerInfo, error) {
+	ctx, cancel := c.withRequestTimeout(ctx)
+	defer cancel()
+
+	reqID := c.requestIDGen()
+	c.logf(reqID, "fetching server info")
+
+	var info ServerInfo
+	if _, err := c.call(ctx, reqID, http.MethodGet, c.apiPath("version"), http.NoBody, &info); err != nil {
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+			return ServerInfo{}, fmt.Errorf("server info: %w", ErrNotSupported)
+		}
+		return ServerInfo{}, err
+	}
+	return info, nil
+}
@@ -0,0 +1,75 @@
+package turn
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestServerInfo(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/v1/version" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if _, err := w.Write([]byte(`{"commit":"abc123","version":"v1.2.3","build_time":"2024-06-01T12:00:00Z"}`)); err != nil {
+			t.Errorf("failed to write response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	client, err := New(WithBackend(server.URL))
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	info, err := client.ServerInfo(context.Background())
+	if err != nil {
+		t.Fatalf("ServerInfo() failed: %v", err)
+	}
+	want := ServerInfo{Commit: "abc123", Version: "v1.2.3", BuildTime: time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)}
+	if info != want {
+		t.Errorf("ServerInfo() = %+v, want %+v", info, want)
+	}
+}
+
+func TestServerInfoEndpointPrefix(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/turn/v1/version" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if _, err := w.Write([]byte(`{"version":"v1.2.3"}`)); err != nil {
+			t.Errorf("failed to write response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	client, err := New(WithBackend(server.URL), WithEndpointPath("/turn/v1/validate"))
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	info, err := client.ServerInfo(context.Background())
+	if err != nil {
+		t.Fatalf("ServerInfo() failed: %v", err)
+	}
+	if info.Version != "v1.2.3" {
+		t.Errorf("Version = %q, want v1.2.3", info.Version)
+	}
+}
+
+func TestServerInfoNotSupported(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	client, err := New(WithBackend(server.URL))
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	if _, err := client.ServerInfo(context.Background()); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("ServerInfo() error = %v, want ErrNotSupported", err)
+	}
+}
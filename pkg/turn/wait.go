@@ -0,0 +1,76 @@
+package turn
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+)
+
+const defaultWaitInterval = 30 * time.Second
+
+// ErrWaitTimeout is returned by WaitUntilReady when WaitOptions.MaxWait elapses.
+var ErrWaitTimeout = errors.New("timed out waiting for PR to become ready")
+
+// WaitOptions configures WaitUntilReady.
+type WaitOptions struct {
+	// Done reports whether polling can stop. Defaults to stopping once the PR
+	// is ready to merge, merged, or closed.
+	Done func(*CheckResponse) bool
+	// Interval between polls; up to 10% jitter is added. Defaults to 30s.
+	Interval time.Duration
+	// MaxWait bounds the total time spent polling. Zero means no limit
+	// beyond the context's own deadline.
+	MaxWait time.Duration
+}
+
+// WaitUntilReady polls Check until opts.Done reports true, the context is
+// cancelled, or opts.MaxWait elapses. Each poll passes the current time as
+// updatedAt so that cached results are not reused, and does not send
+// If-Modified-Since, since every poll would otherwise be answered 304.
+// On timeout or cancellation the most recent response is returned along with
+// the error; ErrWaitTimeout indicates MaxWait elapsed.
+func (c *Client) WaitUntilReady(ctx context.Context, prURL, user string, opts WaitOptions) (*CheckResponse, error) {
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = defaultWaitInterval
+	}
+	done := opts.Done
+	if done == nil {
+		done = isReadyOrClosed
+	}
+	if opts.MaxWait > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, opts.MaxWait, ErrWaitTimeout)
+		defer cancel()
+	}
+
+	var last *CheckResponse
+	for attempt := 1; ; attempt++ {
+		result, err := c.CheckWithOptions(ctx, prURL, user, c.now(), CheckOptions{unconditional: true})
+		if err != nil {
+			if ctx.Err() != nil {
+				return last, context.Cause(ctx)
+			}
+			return last, err
+		}
+		last = result
+		if done(result) {
+			return result, nil
+		}
+
+		wait := interval + rand.N(interval/10+1) //nolint:gosec // jitter does not need a secure source
+		c.logf("", "PR %s not ready after %d polls, waiting %v", prURL, attempt, wait)
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return last, context.Cause(ctx)
+		case <-timer.C:
+		}
+	}
+}
+
+func isReadyOrClosed(r *CheckResponse) bool {
+	return r.Analysis.ReadyToMerge || r.PullRequest.Merged || r.PullRequest.State == "closed"
+}
@@ -0,0 +1,115 @@
+package turn
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWaitUntilReady(t *testing.T) {
+	var polls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		n := polls.Add(1)
+		if err := json.NewEncoder(w).Encode(CheckResponse{
+			Analysis: Analysis{ReadyToMerge: n >= 3, UnresolvedComments: int(n)},
+		}); err != nil {
+			t.Errorf("failed to encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	client, err := New(WithBackend(server.URL))
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	ctx := context.Background()
+	prURL := "https://github.com/owner/repo/pull/123"
+
+	t.Run("default predicate", func(t *testing.T) {
+		polls.Store(0)
+		result, err := client.WaitUntilReady(ctx, prURL, "alice", WaitOptions{Interval: 10 * time.Millisecond})
+		if err != nil {
+			t.Fatalf("WaitUntilReady() failed: %v", err)
+		}
+		if !result.Analysis.ReadyToMerge || polls.Load() != 3 {
+			t.Errorf("got ready=%v after %d polls, want ready after 3", result.Analysis.ReadyToMerge, polls.Load())
+		}
+	})
+
+	t.Run("custom predicate", func(t *testing.T) {
+		polls.Store(0)
+		result, err := client.WaitUntilReady(ctx, prURL, "alice", WaitOptions{
+			Interval: 10 * time.Millisecond,
+			Done:     func(r *CheckResponse) bool { return r.Analysis.UnresolvedComments >= 2 },
+		})
+		if err != nil {
+			t.Fatalf("WaitUntilReady() failed: %v", err)
+		}
+		if result.Analysis.UnresolvedComments != 2 {
+			t.Errorf("stopped at poll %d, want 2", result.Analysis.UnresolvedComments)
+		}
+	})
+
+	t.Run("max wait", func(t *testing.T) {
+		polls.Store(0)
+		result, err := client.WaitUntilReady(ctx, prURL, "alice", WaitOptions{
+			Interval: 20 * time.Millisecond,
+			MaxWait:  30 * time.Millisecond,
+			Done:     func(*CheckResponse) bool { return false },
+		})
+		if !errors.Is(err, ErrWaitTimeout) {
+			t.Errorf("expected ErrWaitTimeout, got %v", err)
+		}
+		if result == nil {
+			t.Error("expected last response to be returned on timeout")
+		}
+	})
+
+	t.Run("context cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(ctx, 30*time.Millisecond)
+		defer cancel()
+		_, err := client.WaitUntilReady(ctx, prURL, "alice", WaitOptions{
+			Interval: 20 * time.Millisecond,
+			Done:     func(*CheckResponse) bool { return false },
+		})
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected context.DeadlineExceeded, got %v", err)
+		}
+	})
+}
+
+func TestWaitUntilReadyConditionalServer(t *testing.T) {
+	// The server honors If-Modified-Since: the PR last changed an hour ago,
+	// so any poll that asks is told nothing changed.
+	lastModified := time.Now().Add(-time.Hour)
+	var polls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ims, err := http.ParseTime(r.Header.Get("If-Modified-Since")); err == nil && !lastModified.After(ims) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		n := polls.Add(1)
+		if err := json.NewEncoder(w).Encode(CheckResponse{Analysis: Analysis{ReadyToMerge: n >= 2}}); err != nil {
+			t.Errorf("failed to encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	client, err := New(WithBackend(server.URL))
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	result, err := client.WaitUntilReady(context.Background(), "https://github.com/owner/repo/pull/123", "alice",
+		WaitOptions{Interval: 10 * time.Millisecond, MaxWait: 5 * time.Second})
+	if err != nil {
+		t.Fatalf("WaitUntilReady() failed: %v", err)
+	}
+	if !result.Analysis.ReadyToMerge || polls.Load() != 2 {
+		t.Errorf("got ready=%v after %d full responses, want ready after 2", result.Analysis.ReadyToMerge, polls.Load())
+	}
+}
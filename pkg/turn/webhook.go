@@ -0,0 +1,57 @@
+package turn
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// webhookPayload holds the fields of a GitHub pull_request or
+// pull_request_review webhook payload that identify the PR and its freshness.
+type webhookPayload struct {
+	PullRequest *struct {
+		UpdatedAt time.Time `json:"updated_at"`
+		HTMLURL   string    `json:"html_url"`
+	} `json:"pull_request"`
+	Review *struct {
+		SubmittedAt time.Time `json:"submitted_at"`
+	} `json:"review"`
+}
+
+// RequestFromWebhook builds a CheckRequest from a GitHub webhook delivery.
+// eventType is the value of the X-GitHub-Event header; "pull_request" and
+// "pull_request_review" are supported. The returned request has no User set,
+// since the payload does not say whose perspective to check from.
+//
+// For reviews, UpdatedAt is the later of the PR's updated_at and the review's
+// submitted_at, as GitHub does not always bump the former in the payload.
+func RequestFromWebhook(eventType string, payload []byte) (CheckRequest, error) {
+	if eventType != "pull_request" && eventType != "pull_request_review" {
+		return CheckRequest{}, fmt.Errorf("unsupported webhook event type %q", eventType)
+	}
+
+	var p webhookPayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return CheckRequest{}, fmt.Errorf("decode %s payload: %w", eventType, err)
+	}
+	if p.PullRequest == nil {
+		return CheckRequest{}, fmt.Errorf("%s payload has no pull_request", eventType)
+	}
+	if p.PullRequest.HTMLURL == "" {
+		return CheckRequest{}, errors.New("pull_request.html_url is missing")
+	}
+	if err := validatePRURL(p.PullRequest.HTMLURL); err != nil {
+		return CheckRequest{}, err
+	}
+
+	updatedAt := p.PullRequest.UpdatedAt
+	if p.Review != nil && p.Review.SubmittedAt.After(updatedAt) {
+		updatedAt = p.Review.SubmittedAt
+	}
+	if updatedAt.IsZero() {
+		return CheckRequest{}, errors.New("pull_request.updated_at is missing")
+	}
+
+	return CheckRequest{URL: p.PullRequest.HTMLURL, UpdatedAt: updatedAt.UTC()}, nil
+}
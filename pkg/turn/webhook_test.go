@@ -0,0 +1,69 @@
+package turn
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRequestFromWebhook(t *testing.T) {
+	prURL := "https://github.com/owner/repo/pull/42"
+	updated := time.Date(2025, 3, 16, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		eventType string
+		payload   string
+		want      time.Time
+		wantErr   bool
+	}{
+		{
+			name:      "pull_request",
+			eventType: "pull_request",
+			payload:   `{"action":"opened","pull_request":{"html_url":"` + prURL + `","updated_at":"2025-03-16T12:00:00Z"}}`,
+			want:      updated,
+		},
+		{
+			name:      "review submitted after update",
+			eventType: "pull_request_review",
+			payload: `{"review":{"submitted_at":"2025-03-16T12:05:00Z"},` +
+				`"pull_request":{"html_url":"` + prURL + `","updated_at":"2025-03-16T12:00:00Z"}}`,
+			want: updated.Add(5 * time.Minute),
+		},
+		{
+			name:      "offset converted to UTC",
+			eventType: "pull_request",
+			payload:   `{"pull_request":{"html_url":"` + prURL + `","updated_at":"2025-03-16T14:00:00+02:00"}}`,
+			want:      updated,
+		},
+		{name: "unsupported event", eventType: "push", payload: `{}`, wantErr: true},
+		{name: "invalid JSON", eventType: "pull_request", payload: `{`, wantErr: true},
+		{name: "no pull_request", eventType: "pull_request", payload: `{"action":"opened"}`, wantErr: true},
+		{
+			name:      "missing updated_at",
+			eventType: "pull_request",
+			payload:   `{"pull_request":{"html_url":"` + prURL + `"}}`,
+			wantErr:   true,
+		},
+		{
+			name:      "not a PR URL",
+			eventType: "pull_request",
+			payload:   `{"pull_request":{"html_url":"https://github.com/owner/repo","updated_at":"2025-03-16T12:00:00Z"}}`,
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RequestFromWebhook(tt.eventType, []byte(tt.payload))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RequestFromWebhook() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.URL != prURL || !got.UpdatedAt.Equal(tt.want) || got.UpdatedAt.Location() != time.UTC {
+				t.Errorf("RequestFromWebhook() = %+v, want URL %s and UpdatedAt %v", got, prURL, tt.want)
+			}
+		})
+	}
+}